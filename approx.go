@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"hash/maphash"
+	"io"
+
+	"github.com/alcionai/clues/clog"
+)
+
+const (
+	// the dimensions of the count-min sketch.  Estimates overcount
+	// by at most e/sketchWidth of all sketched occurrences, with a
+	// probability of 1-e^-sketchDepth.
+	sketchWidth = 1 << 16
+	sketchDepth = 4
+)
+
+// countMinSketch approximates the count of each key in a fixed amount
+// of memory.  Estimates can overcount, but never undercount.
+type countMinSketch struct {
+	seeds  [sketchDepth]maphash.Seed
+	counts [sketchDepth][]int64
+	// the count of all occurrences added to the sketch.
+	total int64
+}
+
+func newCountMinSketch() *countMinSketch {
+	cms := &countMinSketch{}
+
+	for i := range sketchDepth {
+		cms.seeds[i] = maphash.MakeSeed()
+		cms.counts[i] = make([]int64, sketchWidth)
+	}
+
+	return cms
+}
+
+func (cms *countMinSketch) inc(key string) {
+	for i := range sketchDepth {
+		cms.counts[i][maphash.String(cms.seeds[i], key)%sketchWidth]++
+	}
+
+	cms.total++
+}
+
+func (cms *countMinSketch) estimate(key string) int64 {
+	var est int64
+
+	for i := range sketchDepth {
+		n := cms.counts[i][maphash.String(cms.seeds[i], key)%sketchWidth]
+		if i == 0 || n < est {
+			est = n
+		}
+	}
+
+	return est
+}
+
+// sketched is true if the word should be counted approximately.  Words
+// that are already counted exactly stay exact.  Once the exact words
+// reach the auto-approx-at limit, all new words are sketched instead.
+func (h *handler) sketched(ctx context.Context, word string) bool {
+	if h.autoApproxAt == 0 || h.normalizePerFile {
+		return false
+	}
+
+	if _, ok := h.words.universal.Load(word); ok {
+		return false
+	}
+
+	if h.sketch == nil {
+		if h.words.universal.Size() < h.autoApproxAt {
+			return false
+		}
+
+		clog.Ctx(ctx).
+			With("auto_approx_at", h.autoApproxAt, "lines_seen", h.linesSeen).
+			Info("unique word limit reached; counting new words approximately")
+
+		h.sketch = newCountMinSketch()
+	}
+
+	return true
+}
+
+// incSketched counts a word into the sketch, and into the totals of
+// the word stats.  The word itself is never added to the word tables.
+func (h *handler) incSketched(word string, removed bool) {
+	h.sketch.inc(word)
+
+	h.words.count.Inc()
+	h.words.countSwapped.Inc()
+
+	if removed {
+		h.words.countRemoved.Inc()
+	} else {
+		h.words.countBoth.Inc()
+	}
+}
+
+// wordCount produces the count of the raw word, estimating from the
+// sketch if the word wasn't counted exactly.
+func (h *handler) wordCount(word string) int64 {
+	if v, ok := h.words.universal.Load(word); ok {
+		return v.Value()
+	}
+
+	if h.sketch != nil {
+		return h.sketch.estimate(word)
+	}
+
+	return 0
+}
+
+// printApproximate writes a footer noting that the word tables are
+// approximate, and how many occurrences were sketched.
+func printApproximate(cms *countMinSketch, limit int, w io.Writer) {
+	writeLn(w, fmt.Sprintf(
+		"approximate: %s words past the first %s unique words were counted approximately, and are missing from the word tables",
+		human(cms.total),
+		human(limit),
+	))
+}
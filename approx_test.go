@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCountMinSketch(t *testing.T) {
+	cms := newCountMinSketch()
+
+	for range 5 {
+		cms.inc("common")
+	}
+
+	cms.inc("rare")
+
+	// estimates never undercount.
+	if got := cms.estimate("common"); got < 5 {
+		t.Errorf("estimate of common = %d, want at least 5", got)
+	}
+
+	if got := cms.estimate("rare"); got < 1 {
+		t.Errorf("estimate of rare = %d, want at least 1", got)
+	}
+
+	if cms.total != 6 {
+		t.Errorf("sketch total = %d, want 6", cms.total)
+	}
+}
+
+func TestAutoApprox(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "a b c d e a d d\n")
+
+	h, report := runCount(t, "--auto-approx-at=3", input)
+
+	if h.sketch == nil {
+		t.Fatal("counting never switched to the sketch")
+	}
+
+	// words past the limit are sketched, while words already counted
+	// exactly stay exact.
+	if got := h.words.universal.Size(); got != 3 {
+		t.Errorf("exact words = %d, want 3", got)
+	}
+
+	if got := countOf(h.words.universal, "a"); got != 2 {
+		t.Errorf("count of a = %d, want 2", got)
+	}
+
+	if got := h.wordCount("d"); got < 3 {
+		t.Errorf("estimated count of d = %d, want at least 3", got)
+	}
+
+	if got := h.words.count.Value(); got != 8 {
+		t.Errorf("word count = %d, want 8", got)
+	}
+
+	want := "approximate: 4 words past the first 3 unique words were counted approximately"
+	if !strings.Contains(report, want) {
+		t.Errorf("report isn't flagged approximate:\n%s", report)
+	}
+}
+
+func TestAutoApproxJSON(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "a b c d e a d d\n")
+
+	table := []struct {
+		name  string
+		limit string
+		want  bool
+	}{
+		{"past the limit", "--auto-approx-at=3", true},
+		{"within the limit", "--auto-approx-at=10", false},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			_, report := runCount(t, "--format=json", test.limit, input)
+
+			var r results
+
+			if err := json.Unmarshal([]byte(report), &r); err != nil {
+				t.Fatalf("unmarshalling json: %v\n%s", err, report)
+			}
+
+			if r.Words.Approximate != test.want {
+				t.Errorf("words approximate = %v, want %v", r.Words.Approximate, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/alcionai/clues/cluerr"
+)
+
+// archiveExts holds the extensions of all archives whose members
+// get counted, once any compression extension is removed.
+var archiveExts = []string{".zip", ".tar"}
+
+// isArchive is true if the path is a zip or tar archive.  Tar
+// archives can also be compressed (ex: .tar.gz).
+func isArchive(p string) bool {
+	p = trimCompressedExt(p)
+
+	for _, ext := range archiveExts {
+		if strings.HasSuffix(p, ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// memberPath names an archive member as if the archive were
+// a directory.
+func memberPath(archive, member string) string {
+	return archive + "/" + member
+}
+
+// runArchive counts every counted member of the archive as its
+// own corpus.
+func (h *handler) runArchive(
+	ctx context.Context,
+	archivePath string,
+) error {
+	if strings.HasSuffix(archivePath, ".zip") {
+		return h.runZip(ctx, archivePath)
+	}
+
+	f, err := openInput(ctx, archivePath)
+	if err != nil {
+		return cluerr.WrapWC(ctx, err, "opening input: "+archivePath)
+	}
+
+	defer f.Close()
+
+	return h.runTar(ctx, archivePath, tar.NewReader(f))
+}
+
+func (h *handler) runTar(
+	ctx context.Context,
+	archivePath string,
+	tr *tar.Reader,
+) error {
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return cluerr.WrapWC(ctx, err, "reading archive: "+archivePath)
+		}
+
+		if hdr.Typeflag != tar.TypeReg || !h.inputs.counts(hdr.Name) {
+			continue
+		}
+
+		if err := h.runMember(ctx, memberPath(archivePath, hdr.Name), io.NopCloser(tr)); err != nil {
+			return err
+		}
+	}
+}
+
+// runZip counts the members of a local zip archive.  Unlike tar, zip
+// archives must be read from disk, since their index is at the end.
+func (h *handler) runZip(
+	ctx context.Context,
+	archivePath string,
+) error {
+	if !isLocal(archivePath) {
+		return cluerr.NewWC(ctx, "zip archives must be local files: "+archivePath)
+	}
+
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return cluerr.WrapWC(ctx, err, "opening archive: "+archivePath)
+	}
+
+	defer zr.Close()
+
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() || !h.inputs.counts(zf.Name) {
+			continue
+		}
+
+		rc, err := zf.Open()
+		if err != nil {
+			return cluerr.WrapWC(ctx, err, "opening archive member: "+zf.Name)
+		}
+
+		if err := h.runMember(ctx, memberPath(archivePath, zf.Name), rc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runMember counts a single archive member, decompressing it if
+// needed.  The member gets closed once it's counted.
+func (h *handler) runMember(
+	ctx context.Context,
+	name string,
+	rc io.ReadCloser,
+) error {
+	r, err := decompress(path.Base(name), rc)
+	if err != nil {
+		return cluerr.WrapWC(ctx, err, "opening archive member: "+name)
+	}
+
+	defer r.Close()
+
+	return h.runReader(ctx, name, r)
+}
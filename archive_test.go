@@ -0,0 +1,211 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// archiveMember is a file, or a directory when its text is empty
+// and its name ends in a slash.
+type archiveMember struct {
+	name, text string
+}
+
+var testArchiveMembers = []archiveMember{
+	{"top.txt", "one two\n"},
+	{"dir/", ""},
+	{"dir/nested/", ""},
+	{"dir/nested/deep.txt", "three four five\n"},
+	{"dir/notes.md", "never counted\n"},
+}
+
+var testArchiveCounts = map[string]int64{
+	"top.txt":             2,
+	"dir/nested/deep.txt": 3,
+}
+
+func writeTar(t *testing.T, w io.Writer, members []archiveMember) {
+	t.Helper()
+
+	tw := tar.NewWriter(w)
+
+	for _, m := range members {
+		hdr := &tar.Header{
+			Name:     m.name,
+			Mode:     0o644,
+			Size:     int64(len(m.text)),
+			Typeflag: tar.TypeReg,
+		}
+
+		if m.text == "" {
+			hdr.Typeflag = tar.TypeDir
+			hdr.Mode = 0o755
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("writing tar header: %v", err)
+		}
+
+		if _, err := io.WriteString(tw, m.text); err != nil {
+			t.Fatalf("writing tar member: %v", err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatalf("closing tar: %v", err)
+	}
+}
+
+func writeZip(t *testing.T, w io.Writer, members []archiveMember) {
+	t.Helper()
+
+	zw := zip.NewWriter(w)
+
+	for _, m := range members {
+		fw, err := zw.Create(m.name)
+		if err != nil {
+			t.Fatalf("creating zip member: %v", err)
+		}
+
+		if _, err := io.WriteString(fw, m.text); err != nil {
+			t.Fatalf("writing zip member: %v", err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatalf("closing zip: %v", err)
+	}
+}
+
+// createFile creates the file at path, closing it when the test ends.
+func createFile(t *testing.T, path string) *os.File {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("creating %s: %v", path, err)
+	}
+
+	t.Cleanup(func() { f.Close() })
+
+	return f
+}
+
+func TestArchiveInputs(t *testing.T) {
+	table := []struct {
+		name  string
+		file  string
+		write func(t *testing.T, w io.Writer)
+	}{
+		{
+			name: "tar",
+			file: "corpus.tar",
+			write: func(t *testing.T, w io.Writer) {
+				writeTar(t, w, testArchiveMembers)
+			},
+		},
+		{
+			name: "tar.gz",
+			file: "corpus.tar.gz",
+			write: func(t *testing.T, w io.Writer) {
+				gw := gzip.NewWriter(w)
+				writeTar(t, gw, testArchiveMembers)
+
+				if err := gw.Close(); err != nil {
+					t.Fatalf("closing gzip: %v", err)
+				}
+			},
+		},
+		{
+			name: "zip",
+			file: "corpus.zip",
+			write: func(t *testing.T, w io.Writer) {
+				writeZip(t, w, testArchiveMembers)
+			},
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			archive := filepath.Join(t.TempDir(), test.file)
+			f := createFile(t, archive)
+
+			test.write(t, f)
+
+			if err := f.Close(); err != nil {
+				t.Fatalf("closing archive: %v", err)
+			}
+
+			h, _ := runCount(t, "--per-file", archive)
+
+			got := map[string]int64{}
+
+			for _, fc := range h.files {
+				got[fc.path] = fc.words.count.Value()
+			}
+
+			want := map[string]int64{}
+
+			for member, count := range testArchiveCounts {
+				want[memberPath(archive, member)] = count
+			}
+
+			if !maps.Equal(got, want) {
+				t.Errorf("member word counts = %v, want %v", got, want)
+			}
+
+			if got := h.words.count.Value(); got != 5 {
+				t.Errorf("word count = %d, want 5", got)
+			}
+		})
+	}
+}
+
+func TestCompressedArchiveMember(t *testing.T) {
+	var (
+		zipped  bytes.Buffer
+		archive = filepath.Join(t.TempDir(), "corpus.tar")
+		f       = createFile(t, archive)
+		tw      = tar.NewWriter(f)
+		gw      = gzip.NewWriter(&zipped)
+	)
+
+	if _, err := io.WriteString(gw, "zipped words\n"); err != nil {
+		t.Fatalf("writing gzip: %v", err)
+	}
+
+	if err := gw.Close(); err != nil {
+		t.Fatalf("closing gzip: %v", err)
+	}
+
+	hdr := &tar.Header{Name: "member.txt.gz", Mode: 0o644, Size: int64(zipped.Len())}
+
+	if err := tw.WriteHeader(hdr); err != nil {
+		t.Fatalf("writing tar header: %v", err)
+	}
+
+	if _, err := tw.Write(zipped.Bytes()); err != nil {
+		t.Fatalf("writing tar member: %v", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatalf("closing tar: %v", err)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatalf("closing archive: %v", err)
+	}
+
+	h, _ := runCount(t, archive)
+
+	if got := countOf(h.words.universal, "zipped"); got != 1 {
+		t.Errorf("count of zipped = %d, want 1", got)
+	}
+}
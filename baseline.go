@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/alcionai/clues/cluerr"
+)
+
+// drift is a single value whose frequency moved away from
+// its frequency in the baseline.
+type drift struct {
+	section, column, value string
+	baseline, current      float64
+}
+
+// fullResults produces every count in the word and letter stats,
+// without limiting them to the displayed units.
+func (h *handler) fullResults() results {
+	return results{
+		Words:   toSection(h.words, view{}),
+		Letters: toSection(h.letters, view{}),
+	}
+}
+
+// writeBaseline writes the full results to the file at path, for
+// later runs to compare against with --diff-against-baseline-json.
+func (h *handler) writeBaseline(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return cluerr.Wrap(err, "creating baseline file")
+	}
+
+	defer f.Close()
+
+	if err := writeJSON(h.fullResults(), f); err != nil {
+		return err
+	}
+
+	return cluerr.Wrap(f.Close(), "closing baseline file").OrNil()
+}
+
+// loadBaseline reads a set of results previously written with
+// --save-baseline-json.
+func loadBaseline(path string) (results, error) {
+	var r results
+
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return r, cluerr.Wrap(err, "reading baseline")
+	}
+
+	if err := json.Unmarshal(bs, &r); err != nil {
+		return r, cluerr.Wrap(err, "parsing baseline json")
+	}
+
+	return r, nil
+}
+
+// diffBaseline compares the frequency of every value in either the
+// baseline or the current results, and returns each value whose
+// frequency changed by more than tolerance percentage points.  Both
+// results must be complete (not limited to a top set), so that values
+// missing from either one are known to be zero.
+func diffBaseline(
+	baseline, current results,
+	tolerance float64,
+) []drift {
+	var drifts []drift
+
+	sections := []struct {
+		name              string
+		baseline, current section
+	}{
+		{"words", baseline.Words, current.Words},
+		{"letters", baseline.Letters, current.Letters},
+	}
+
+	for _, sect := range sections {
+		columns := []struct {
+			name              string
+			baseline, current []entry
+		}{
+			{"raw", sect.baseline.Raw, sect.current.Raw},
+			{"removed", sect.baseline.Removed, sect.current.Removed},
+			{"swapped", sect.baseline.Swapped, sect.current.Swapped},
+			{"both", sect.baseline.Both, sect.current.Both},
+		}
+
+		for _, col := range columns {
+			var (
+				base   = map[string]float64{}
+				curr   = map[string]float64{}
+				values []string
+			)
+
+			// baseline values first, then any values that are new
+			// since the baseline, each in their frequency order.
+			for _, e := range col.baseline {
+				base[e.Value] = e.Percent
+				values = append(values, e.Value)
+			}
+
+			for _, e := range col.current {
+				curr[e.Value] = e.Percent
+
+				if _, ok := base[e.Value]; !ok {
+					values = append(values, e.Value)
+				}
+			}
+
+			for _, v := range values {
+				if math.Abs(curr[v]-base[v]) <= tolerance {
+					continue
+				}
+
+				drifts = append(drifts, drift{
+					section:  sect.name,
+					column:   col.name,
+					value:    v,
+					baseline: base[v],
+					current:  curr[v],
+				})
+			}
+		}
+	}
+
+	return drifts
+}
+
+// checkBaseline reports all values that drifted from the baseline
+// beyond the tolerance, and errors if there were any.  The report is
+// kept apart from the results (ex: on stderr), so that it can't
+// corrupt structured output.
+func (h *handler) checkBaseline(w io.Writer) error {
+	baseline, err := loadBaseline(h.baseline)
+	if err != nil {
+		return cluerr.Wrap(err, "loading baseline").With("baseline", h.baseline)
+	}
+
+	drifts := diffBaseline(baseline, h.fullResults(), h.tolerance)
+	if len(drifts) == 0 {
+		return nil
+	}
+
+	writeLn(w, fmt.Sprintf("baseline drift (tolerance %.2f%%)", h.tolerance))
+	writeLn(w, "| section | column | value | baseline | current |")
+	writeLn(w, "|---|---|---|---|---|")
+
+	for _, d := range drifts {
+		writeLn(w, fmt.Sprintf(
+			"| %s | %s | %s | %2.2f%% | %2.2f%% |",
+			d.section,
+			d.column,
+			d.value,
+			d.baseline,
+			d.current,
+		))
+	}
+
+	return cluerr.New("frequencies drifted from the baseline beyond the tolerance").
+		With("drifts", len(drifts), "tolerance", h.tolerance)
+}
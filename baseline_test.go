@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBaselineUnchanged(t *testing.T) {
+	var (
+		dir   = t.TempDir()
+		base  = filepath.Join(dir, "base.json")
+		input = writeInput(t, dir, "in.txt", "the cat sat on the mat\n")
+	)
+
+	runCount(t, "--save-baseline-json="+base, input)
+
+	_, report := runCount(t, "--diff-against-baseline-json="+base, input)
+
+	if strings.Contains(report, "baseline drift") {
+		t.Errorf("drift was reported into the results:\n%s", report)
+	}
+}
+
+func TestBaselineDrift(t *testing.T) {
+	var (
+		dir     = t.TempDir()
+		base    = filepath.Join(dir, "base.json")
+		input   = writeInput(t, dir, "in.txt", "the cat sat on the mat\n")
+		changed = writeInput(t, dir, "changed.txt", "the cat sat on the zebra\n")
+	)
+
+	runCount(t, "--save-baseline-json="+base, input)
+
+	err := execCount(newHandler(), "--diff-against-baseline-json="+base, "--tolerance=0.5", "-o", filepath.Join(dir, "out.txt"), changed)
+	if err == nil {
+		t.Fatal("expected an error for frequencies that drifted from the baseline")
+	}
+
+	// within the tolerance, the same change passes.
+	runCount(t, "--diff-against-baseline-json="+base, "--tolerance=100", changed)
+}
+
+func TestCheckBaselineReport(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		base = filepath.Join(dir, "base.json")
+		h    = newHandler()
+		ctx  = context.Background()
+	)
+
+	h.processLine(ctx, []string{"the", "cat"})
+
+	if err := h.writeBaseline(base); err != nil {
+		t.Fatalf("writing baseline: %v", err)
+	}
+
+	h.processLine(ctx, []string{"zebra"})
+	h.baseline = base
+
+	var w bytes.Buffer
+
+	if err := h.checkBaseline(&w); err == nil {
+		t.Error("expected an error for frequencies that drifted from the baseline")
+	}
+
+	// the new word drifts from nothing in the baseline.
+	if !strings.Contains(w.String(), "| words | raw | zebra | 0.00% | 33.33% |") {
+		t.Errorf("drift report is missing the new word:\n%s", w.String())
+	}
+}
+
+func TestDiffBaseline(t *testing.T) {
+	var (
+		baseline = results{Words: section{Raw: []entry{
+			{Value: "the", Percent: 50},
+			{Value: "cat", Percent: 30},
+			{Value: "gone", Percent: 20},
+		}}}
+		current = results{Words: section{Raw: []entry{
+			{Value: "the", Percent: 50.5},
+			{Value: "cat", Percent: 30},
+			{Value: "new", Percent: 19.5},
+		}}}
+	)
+
+	var values []string
+
+	for _, d := range diffBaseline(baseline, current, 1) {
+		values = append(values, d.value)
+	}
+
+	if want := []string{"gone", "new"}; !slices.Equal(values, want) {
+		t.Errorf("drifted values = %v, want %v", values, want)
+	}
+}
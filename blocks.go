@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/puzpuzpuz/xsync/v4"
+)
+
+// unicodeBlock is a named, contiguous range of code points.
+type unicodeBlock struct {
+	lo, hi rune
+	name   string
+}
+
+// otherBlock collects any rune not found in unicodeBlocks.
+const otherBlock = "Other"
+
+// unicodeBlocks holds the blocks most likely to appear in text
+// corpora, ordered by code point.  The go stdlib only classifies
+// runes by script and category, not by block.
+var unicodeBlocks = []unicodeBlock{
+	{0x0000, 0x007F, "Basic Latin"},
+	{0x0080, 0x00FF, "Latin-1 Supplement"},
+	{0x0100, 0x017F, "Latin Extended-A"},
+	{0x0180, 0x024F, "Latin Extended-B"},
+	{0x0250, 0x02AF, "IPA Extensions"},
+	{0x02B0, 0x02FF, "Spacing Modifier Letters"},
+	{0x0300, 0x036F, "Combining Diacritical Marks"},
+	{0x0370, 0x03FF, "Greek and Coptic"},
+	{0x0400, 0x04FF, "Cyrillic"},
+	{0x0500, 0x052F, "Cyrillic Supplement"},
+	{0x0530, 0x058F, "Armenian"},
+	{0x0590, 0x05FF, "Hebrew"},
+	{0x0600, 0x06FF, "Arabic"},
+	{0x0700, 0x074F, "Syriac"},
+	{0x0900, 0x097F, "Devanagari"},
+	{0x0980, 0x09FF, "Bengali"},
+	{0x0A00, 0x0A7F, "Gurmukhi"},
+	{0x0A80, 0x0AFF, "Gujarati"},
+	{0x0B80, 0x0BFF, "Tamil"},
+	{0x0C00, 0x0C7F, "Telugu"},
+	{0x0E00, 0x0E7F, "Thai"},
+	{0x10A0, 0x10FF, "Georgian"},
+	{0x1100, 0x11FF, "Hangul Jamo"},
+	{0x16A0, 0x16FF, "Runic"},
+	{0x1E00, 0x1EFF, "Latin Extended Additional"},
+	{0x1F00, 0x1FFF, "Greek Extended"},
+	{0x2000, 0x206F, "General Punctuation"},
+	{0x20A0, 0x20CF, "Currency Symbols"},
+	{0x2100, 0x214F, "Letterlike Symbols"},
+	{0x2190, 0x21FF, "Arrows"},
+	{0x2200, 0x22FF, "Mathematical Operators"},
+	{0x2500, 0x257F, "Box Drawing"},
+	{0x2600, 0x26FF, "Miscellaneous Symbols"},
+	{0x2700, 0x27BF, "Dingbats"},
+	{0x3000, 0x303F, "CJK Symbols and Punctuation"},
+	{0x3040, 0x309F, "Hiragana"},
+	{0x30A0, 0x30FF, "Katakana"},
+	{0x4E00, 0x9FFF, "CJK Unified Ideographs"},
+	{0xAC00, 0xD7AF, "Hangul Syllables"},
+	{0xFB00, 0xFB4F, "Alphabetic Presentation Forms"},
+	{0xFF00, 0xFFEF, "Halfwidth and Fullwidth Forms"},
+	{0x1F300, 0x1F5FF, "Miscellaneous Symbols and Pictographs"},
+	{0x1F600, 0x1F64F, "Emoticons"},
+	{0x1F900, 0x1F9FF, "Supplemental Symbols and Pictographs"},
+}
+
+// blockOf produces the index of the block containing r, or -1
+// if r isn't in any known block.
+func blockOf(r rune) int {
+	i, found := slices.BinarySearchFunc(unicodeBlocks, r, func(b unicodeBlock, r rune) int {
+		switch {
+		case r < b.lo:
+			return 1
+		case r > b.hi:
+			return -1
+		default:
+			return 0
+		}
+	})
+
+	if !found {
+		return -1
+	}
+
+	return i
+}
+
+// blockCounts sums the letter occurrences in each block.  Letters made
+// of several runes (ex: e+◌́) fall in the block of their first rune.
+// The stripped pseudo-letter is ignored.
+func blockCounts(letters *xsync.Map[string, *xsync.Counter]) map[int]int64 {
+	counts := map[int]int64{}
+
+	letters.Range(func(key string, value *xsync.Counter) bool {
+		r, _ := utf8.DecodeRuneInString(key)
+		if key != strippedLetter && r != utf8.RuneError {
+			counts[blockOf(r)] += value.Value()
+		}
+
+		return true
+	})
+
+	return counts
+}
+
+// printBlocks writes the count of letter occurrences within each
+// unicode block, for every letters column.
+func printBlocks(
+	stats stats,
+	w io.Writer,
+) {
+	var (
+		columns = []map[int]int64{
+			blockCounts(stats.universal),
+			blockCounts(stats.removed),
+			blockCounts(stats.swapped),
+			blockCounts(stats.both),
+		}
+		totals = make([]int64, len(columns))
+		// every block that appears in any column
+		found = map[int]struct{}{}
+	)
+
+	for i, col := range columns {
+		for block, n := range col {
+			found[block] = struct{}{}
+			totals[i] += n
+		}
+	}
+
+	writeLn(w, "unicode blocks")
+	writeLn(
+		w,
+		"| block "+
+			addCellHeader("raw", totals[0])+
+			addCellHeader("removed", totals[1])+
+			addCellHeader("swapped", totals[2])+
+			addCellHeader("both", totals[3])+
+			"|",
+	)
+	writeLn(w, "|---|---|---|---|---|")
+
+	// the unknown block (-1) sorts first, but is printed last.
+	blocks := slices.Sorted(maps.Keys(found))
+
+	if len(blocks) > 0 && blocks[0] == -1 {
+		blocks = append(blocks[1:], -1)
+	}
+
+	for _, block := range blocks {
+		name := otherBlock
+		if block >= 0 {
+			name = unicodeBlocks[block].name
+		}
+
+		ln := "| " + name + " "
+
+		for i, col := range columns {
+			ln += fmt.Sprintf(
+				"| %6s, %2.2f%% ",
+				human(col[block]),
+				percent(int(col[block]), totals[i]),
+			)
+		}
+
+		writeLn(w, ln+"|")
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestBlockOf(t *testing.T) {
+	table := []struct {
+		r    rune
+		want string
+	}{
+		{'a', "Basic Latin"},
+		{'é', "Latin-1 Supplement"},
+		{'ő', "Latin Extended-A"},
+		{'λ', "Greek and Coptic"},
+		{'ж', "Cyrillic"},
+		{'漢', "CJK Unified Ideographs"},
+		{'😀', "Emoticons"},
+		{'\U000E0001', otherBlock},
+	}
+
+	for _, test := range table {
+		name := otherBlock
+		if i := blockOf(test.r); i >= 0 {
+			name = unicodeBlocks[i].name
+		}
+
+		if name != test.want {
+			t.Errorf("block of %q = %s, want %s", test.r, name, test.want)
+		}
+	}
+}
+
+func TestBlockCounts(t *testing.T) {
+	h := newHandler()
+	h.processLine(context.Background(), []string{"café", "αβγ", "cab"})
+
+	got := map[string]int64{}
+
+	for block, n := range blockCounts(h.letters.universal) {
+		got[unicodeBlocks[block].name] = n
+	}
+
+	want := map[string]int64{
+		"Basic Latin":        6,
+		"Latin-1 Supplement": 1,
+		"Greek and Coptic":   3,
+	}
+
+	if !maps.Equal(got, want) {
+		t.Errorf("block counts = %v, want %v", got, want)
+	}
+}
+
+func TestUnicodeBlocksReport(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "café αβγ cab\n")
+
+	_, report := runCount(t, "--unicode-blocks", input)
+
+	for _, row := range []string{
+		"| block | raw (10) |",
+		"| Basic Latin |      6, 60.00% |",
+		"| Latin-1 Supplement |      1, 10.00% |",
+		"| Greek and Coptic |      3, 30.00% |",
+	} {
+		if !strings.Contains(report, row) {
+			t.Errorf("report is missing %q:\n%s", row, report)
+		}
+	}
+
+	// blocks are printed in code point order.
+	if strings.Index(report, "| Basic Latin") > strings.Index(report, "| Greek and Coptic") {
+		t.Errorf("blocks are out of order:\n%s", report)
+	}
+}
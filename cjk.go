@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/alcionai/clues/cluerr"
+)
+
+// cjkScripts holds the scripts written without spaces between words.
+// Hangul is written with spaces, so it isn't included.
+var cjkScripts = []*unicode.RangeTable{
+	unicode.Han,
+	unicode.Hiragana,
+	unicode.Katakana,
+}
+
+func isCJK(r rune) bool {
+	return unicode.IsOneOf(cjkScripts, r)
+}
+
+// cjkDict is a dictionary of the words of unspaced scripts, weighted
+// by how often each word occurs.
+type cjkDict struct {
+	// the log probability of each word.
+	logProbs map[string]float64
+	// the log probability of a character missing from the dictionary.
+	unknown float64
+	// the length, in runes, of the longest word.
+	longest int
+}
+
+// readCJKDict reads the dictionary at path: one word per line,
+// optionally followed by its frequency, and then by anything else
+// (ex: jieba's "word freq tag" dict.txt).  Words without a frequency
+// count once.  Anything after a # is a comment, and blank lines are
+// skipped.
+func readCJKDict(path string) (*cjkDict, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, cluerr.Wrap(err, "opening cjk dictionary")
+	}
+
+	defer f.Close()
+
+	var (
+		freqs   = map[string]float64{}
+		total   float64
+		rarest  = math.Inf(1)
+		scanner = bufio.NewScanner(f)
+	)
+
+	for scanner.Scan() {
+		ln, _, _ := strings.Cut(scanner.Text(), "#")
+
+		fields := strings.Fields(ln)
+		if len(fields) == 0 {
+			continue
+		}
+
+		freq := 1.0
+
+		if len(fields) > 1 {
+			freq, err = strconv.ParseFloat(fields[1], 64)
+			if err != nil || freq <= 0 {
+				return nil, cluerr.New("cjk dictionary frequencies must be positive numbers").
+					With("input", ln)
+			}
+		}
+
+		freqs[fields[0]] += freq
+		total += freq
+		rarest = min(rarest, freq)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, cluerr.Wrap(err, "reading cjk dictionary")
+	}
+
+	if len(freqs) == 0 {
+		return nil, cluerr.New("cjk dictionary is empty")
+	}
+
+	dict := &cjkDict{
+		logProbs: make(map[string]float64, len(freqs)),
+		// rarer than any word in the dictionary, so that known words
+		// are always preferred over unknown characters.
+		unknown: math.Log(rarest / 2 / total),
+	}
+
+	for word, freq := range freqs {
+		dict.logProbs[word] = math.Log(freq / total)
+		dict.longest = max(dict.longest, utf8.RuneCountInString(word))
+	}
+
+	return dict, nil
+}
+
+// segment splits every run of han, hiragana, and katakana characters
+// in the words into dictionary words, leaving the runs of other
+// characters between them intact (ex: 東京はtokyo becomes 東京, は,
+// tokyo).
+func (d *cjkDict) segment(words []string) []string {
+	segmented := make([]string, 0, len(words))
+
+	for _, word := range words {
+		if !strings.ContainsFunc(word, isCJK) {
+			segmented = append(segmented, word)
+			continue
+		}
+
+		for len(word) > 0 {
+			first, _ := utf8.DecodeRuneInString(word)
+			cjk := isCJK(first)
+
+			end := strings.IndexFunc(word, func(r rune) bool {
+				return isCJK(r) != cjk
+			})
+			if end < 0 {
+				end = len(word)
+			}
+
+			if cjk {
+				segmented = append(segmented, d.segmentRun([]rune(word[:end]))...)
+			} else {
+				segmented = append(segmented, word[:end])
+			}
+
+			word = word[end:]
+		}
+	}
+
+	return segmented
+}
+
+// segmentRun splits a run of unspaced characters into the sequence
+// of words with the greatest combined probability.  Characters missing
+// from the dictionary become words of their own.
+func (d *cjkDict) segmentRun(run []rune) []string {
+	var (
+		// best[i] is the log probability of the best segmentation
+		// of run[:i], which ends with the word run[starts[i]:i].
+		best   = make([]float64, len(run)+1)
+		starts = make([]int, len(run)+1)
+	)
+
+	for i := 1; i <= len(run); i++ {
+		best[i] = best[i-1] + d.unknown
+		starts[i] = i - 1
+
+		for j := max(0, i-d.longest); j < i; j++ {
+			lp, ok := d.logProbs[string(run[j:i])]
+			if ok && best[j]+lp > best[i] {
+				best[i] = best[j] + lp
+				starts[i] = j
+			}
+		}
+	}
+
+	var words []string
+
+	for i := len(run); i > 0; i = starts[i] {
+		words = append(words, string(run[starts[i]:i]))
+	}
+
+	// backtracking collects the words last to first.
+	slices.Reverse(words)
+
+	return words
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testCJKDict = `# word freq tag
+東京 100 ns
+京都 50 ns
+東 10
+京 10
+都 10
+は 80 x
+
+です
+`
+
+func TestCJKSegment(t *testing.T) {
+	dict, err := readCJKDict(writeInput(t, t.TempDir(), "dict.txt", testCJKDict))
+	if err != nil {
+		t.Fatalf("reading dictionary: %v", err)
+	}
+
+	table := []struct {
+		name  string
+		words []string
+		want  []string
+	}{
+		{"dictionary words", []string{"東京は"}, []string{"東京", "は"}},
+		{"most probable split", []string{"東京都"}, []string{"東京", "都"}},
+		{"single characters", []string{"京東"}, []string{"京", "東"}},
+		{"unknown characters", []string{"猫は東京"}, []string{"猫", "は", "東京"}},
+		{"word without a frequency", []string{"東京です"}, []string{"東京", "です"}},
+		{"mixed scripts", []string{"東京はtokyoです"}, []string{"東京", "は", "tokyo", "です"}},
+		{"latin words", []string{"tokyo", "kyoto"}, []string{"tokyo", "kyoto"}},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			got := dict.segment(test.words)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("segment(%q) = %q, want %q", test.words, got, test.want)
+			}
+		})
+	}
+}
+
+func TestReadCJKDictErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	table := []struct {
+		name, path, want string
+	}{
+		{"missing file", filepath.Join(dir, "missing.txt"), "opening cjk dictionary"},
+		{"bad frequency", writeInput(t, dir, "bad.txt", "東京 lots\n"), "must be positive numbers"},
+		{"negative frequency", writeInput(t, dir, "neg.txt", "東京 -1\n"), "must be positive numbers"},
+		{"zero frequency", writeInput(t, dir, "zero.txt", "東京 0\n"), "must be positive numbers"},
+		{"empty", writeInput(t, dir, "empty.txt", "# only comments\n\n"), "cjk dictionary is empty"},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := readCJKDict(test.path)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("error = %v, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestSegmentCJKInput(t *testing.T) {
+	dir := t.TempDir()
+	dict := writeInput(t, dir, "dict.txt", testCJKDict)
+	input := writeInput(t, dir, "in.txt", "東京は東京都\n")
+
+	h, _ := runCount(t, "--segment-cjk="+dict, input)
+
+	for word, want := range map[string]int64{
+		"東京":     2,
+		"は":      1,
+		"都":      1,
+		"東京は東京都": 0,
+	} {
+		if got := countOf(h.words.universal, word); got != want {
+			t.Errorf("count of %s = %d, want %d", word, got, want)
+		}
+	}
+
+	err := execCount(newHandler(), "--segment-cjk="+filepath.Join(dir, "missing.txt"), input)
+	if err == nil || !strings.Contains(err.Error(), "loading cjk dictionary") {
+		t.Errorf("error = %v, want a cjk dictionary error", err)
+	}
+}
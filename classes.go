@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/alcionai/clues/cluerr"
+)
+
+// longClassNames maps the full names of the major unicode general
+// categories onto their one-letter category names.
+var longClassNames = map[string]string{
+	"letter":      "L",
+	"mark":        "M",
+	"number":      "N",
+	"punctuation": "P",
+	"symbol":      "S",
+	"separator":   "Z",
+	"other":       "C",
+}
+
+// classTables produces the range tables of the named unicode classes.
+// Names can be a full major category name (ex: Symbol), a category
+// (ex: Sm), or a script (ex: Greek).
+func classTables(names []string) ([]*unicode.RangeTable, error) {
+	tables := make([]*unicode.RangeTable, 0, len(names))
+
+	for _, name := range names {
+		if short, ok := longClassNames[strings.ToLower(name)]; ok {
+			name = short
+		}
+
+		if t, ok := unicode.Categories[name]; ok {
+			tables = append(tables, t)
+			continue
+		}
+
+		if t, ok := unicode.Scripts[name]; ok {
+			tables = append(tables, t)
+			continue
+		}
+
+		return nil, cluerr.New("unknown unicode class").
+			With("input", name)
+	}
+
+	return tables, nil
+}
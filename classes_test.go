@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+	"unicode"
+)
+
+func TestClassTables(t *testing.T) {
+	tables, err := classTables([]string{"Symbol", "Pd", "Greek"})
+	if err != nil {
+		t.Fatalf("parsing classes: %v", err)
+	}
+
+	for _, r := range []rune{'♥', '+', '-', 'λ'} {
+		if !unicode.IsOneOf(tables, r) {
+			t.Errorf("%q should be within the classes", r)
+		}
+	}
+
+	for _, r := range []rune{'a', '.', '3'} {
+		if unicode.IsOneOf(tables, r) {
+			t.Errorf("%q shouldn't be within the classes", r)
+		}
+	}
+
+	if _, err := classTables([]string{"Symbols"}); err == nil {
+		t.Error("expected an error for an unknown class")
+	}
+}
+
+func TestDenyClassSymbol(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "i ♥ go ☺\n")
+
+	h, report := runCount(t, "--emoji", "--deny-class=Symbol", input)
+
+	for _, symbol := range []string{"♥", "☺"} {
+		if got := countOf(h.letters.universal, symbol); got != 0 {
+			t.Errorf("count of letter %q = %d, want 0", symbol, got)
+		}
+	}
+
+	rows := tableRows(t, report, "letters")
+	if len(rows) != 3 {
+		t.Errorf("letters = %v, want only i, g, and o", rows)
+	}
+
+	// symbols are only denied as letters, not as words.
+	if got := countOf(h.words.universal, "♥"); got != 1 {
+		t.Errorf("count of word ♥ = %d, want 1", got)
+	}
+
+	h, _ = runCount(t, "--emoji", input)
+
+	if got := countOf(h.letters.universal, "♥"); got != 1 {
+		t.Errorf("count of letter ♥ without denying symbols = %d, want 1", got)
+	}
+}
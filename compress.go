@@ -0,0 +1,90 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"strings"
+
+	"github.com/alcionai/clues/cluerr"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	gzipExt = ".gz"
+	zstdExt = ".zst"
+)
+
+// compressedExts holds the extensions of all compressed inputs
+// that get decompressed while reading.
+var compressedExts = []string{gzipExt, zstdExt}
+
+// isGzip is true if the file at path should be gzip (de)compressed.
+func isGzip(path string) bool {
+	return strings.HasSuffix(path, gzipExt)
+}
+
+// trimCompressedExt removes any compression extension from the path.
+func trimCompressedExt(path string) string {
+	for _, ext := range compressedExts {
+		if strings.HasSuffix(path, ext) {
+			return strings.TrimSuffix(path, ext)
+		}
+	}
+
+	return path
+}
+
+// decompress wraps rc in a decompressing reader, if the path has
+// the extension of a compressed input.  Closing the result closes rc.
+func decompress(path string, rc io.ReadCloser) (io.ReadCloser, error) {
+	switch {
+	case isGzip(path):
+		gz, err := gzip.NewReader(rc)
+		if err != nil {
+			rc.Close()
+			return nil, cluerr.Wrap(err, "decompressing gzip")
+		}
+
+		return readCloser{gz, closeAll(gz, rc)}, nil
+
+	case strings.HasSuffix(path, zstdExt):
+		zr, err := zstd.NewReader(rc)
+		if err != nil {
+			rc.Close()
+			return nil, cluerr.Wrap(err, "decompressing zstd")
+		}
+
+		return readCloser{zr, func() error {
+			zr.Close()
+			return rc.Close()
+		}}, nil
+	}
+
+	return rc, nil
+}
+
+// readCloser pairs a reader with the func that closes it.
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (rc readCloser) Close() error {
+	return rc.close()
+}
+
+// closeAll produces a func that closes every closer, in order,
+// returning the first error.
+func closeAll(closers ...io.Closer) func() error {
+	return func() error {
+		var first error
+
+		for _, c := range closers {
+			if err := c.Close(); err != nil && first == nil {
+				first = err
+			}
+		}
+
+		return first
+	}
+}
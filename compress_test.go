@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// gzipCompressed produces the text, gzip compressed.
+func gzipCompressed(t *testing.T, text string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	gw := gzip.NewWriter(&buf)
+
+	if _, err := io.WriteString(gw, text); err != nil {
+		t.Fatalf("writing gzip: %v", err)
+	}
+
+	if err := gw.Close(); err != nil {
+		t.Fatalf("closing gzip: %v", err)
+	}
+
+	return buf.String()
+}
+
+// zstdCompressed produces the text, zstd compressed.
+func zstdCompressed(t *testing.T, text string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	zw, err := zstd.NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("creating zstd writer: %v", err)
+	}
+
+	if _, err := io.WriteString(zw, text); err != nil {
+		t.Fatalf("writing zstd: %v", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatalf("closing zstd: %v", err)
+	}
+
+	return buf.String()
+}
+
+func TestDecompress(t *testing.T) {
+	const text = "compressed words\n"
+
+	table := []struct {
+		name, path, data string
+	}{
+		{"gzip", "book.txt.gz", gzipCompressed(t, text)},
+		{"zstd", "book.txt.zst", zstdCompressed(t, text)},
+		{"uncompressed", "book.txt", text},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			rc, err := decompress(test.path, io.NopCloser(strings.NewReader(test.data)))
+			if err != nil {
+				t.Fatalf("decompressing: %v", err)
+			}
+
+			defer rc.Close()
+
+			got, err := io.ReadAll(rc)
+			if err != nil {
+				t.Fatalf("reading: %v", err)
+			}
+
+			if string(got) != text {
+				t.Errorf("decompressed = %q, want %q", got, text)
+			}
+		})
+	}
+}
+
+func TestDecompressErrors(t *testing.T) {
+	for _, path := range []string{"book.txt.gz", "book.txt.zst"} {
+		rc, err := decompress(path, io.NopCloser(strings.NewReader("not compressed\n")))
+		if err == nil {
+			// zstd only finds the bad frame once it's read.
+			_, err = io.ReadAll(rc)
+			rc.Close()
+		}
+
+		if err == nil {
+			t.Errorf("decompressing %s: expected an error", path)
+		}
+	}
+}
+
+func TestTrimCompressedExt(t *testing.T) {
+	for path, want := range map[string]string{
+		"book.txt.gz":  "book.txt",
+		"book.txt.zst": "book.txt",
+		"book.txt":     "book.txt",
+		"book.gz.txt":  "book.gz.txt",
+	} {
+		if got := trimCompressedExt(path); got != want {
+			t.Errorf("trimCompressedExt(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestCompressedInputs(t *testing.T) {
+	dir := t.TempDir()
+
+	h, _ := runCount(
+		t,
+		writeInput(t, dir, "a.txt.gz", gzipCompressed(t, "zipped\n")),
+		writeInput(t, dir, "b.txt.zst", zstdCompressed(t, "zipped\n")),
+	)
+
+	if got := countOf(h.words.universal, "zipped"); got != 2 {
+		t.Errorf("count of zipped = %d, want 2", got)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/alcionai/clues/cluerr"
+)
+
+// delimiters holds the names of common csv delimiters.
+var delimiters = map[string]rune{
+	"comma":     ',',
+	"tab":       '\t',
+	"semicolon": ';',
+	"pipe":      '|',
+}
+
+// parseDelimiter produces the delimiter named by s, or the rune
+// of s itself, if it is a single rune.
+func parseDelimiter(s string) (rune, error) {
+	if r, ok := delimiters[s]; ok {
+		return r, nil
+	}
+
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError || size != len(s) {
+		return 0, cluerr.New("delimiter must be a single character").
+			With("input", s)
+	}
+
+	// the same restrictions as csv.Writer.Comma
+	if r == '"' || r == '\r' || r == '\n' {
+		return 0, cluerr.New("invalid delimiter").
+			With("input", s)
+	}
+
+	return r, nil
+}
+
+// writeCSV writes the results as one row per entry.  The file
+// column is empty for the aggregate stats.
+func writeCSV(r results, delimiter rune, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = delimiter
+
+	rows := [][]string{{"file", "table", "column", "value", "count", "percent"}}
+
+	rows = append(rows, sectionRows("", "words", r.Words)...)
+	rows = append(rows, sectionRows("", "letters", r.Letters)...)
+
+	for _, fs := range r.Files {
+		rows = append(rows, sectionRows(fs.Path, "words", fs.Words)...)
+		rows = append(rows, sectionRows(fs.Path, "letters", fs.Letters)...)
+	}
+
+	if err := cw.WriteAll(rows); err != nil {
+		return cluerr.Wrap(err, "writing csv")
+	}
+
+	return nil
+}
+
+func sectionRows(file, table string, s section) [][]string {
+	rows := [][]string{}
+
+	for _, col := range []struct {
+		name    string
+		entries []entry
+	}{
+		{"raw", s.Raw},
+		{"removed", s.Removed},
+		{"swapped", s.Swapped},
+		{"both", s.Both},
+	} {
+		for _, e := range col.entries {
+			rows = append(rows, []string{
+				file,
+				table,
+				col.name,
+				e.Value,
+				strconv.Itoa(e.Count),
+				strconv.FormatFloat(e.Percent, 'f', 2, 64),
+			})
+		}
+	}
+
+	return rows
+}
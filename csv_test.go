@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/csv"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseDelimiter(t *testing.T) {
+	table := []struct {
+		in      string
+		want    rune
+		wantErr bool
+	}{
+		{"comma", ',', false},
+		{"tab", '\t', false},
+		{"semicolon", ';', false},
+		{"pipe", '|', false},
+		{"¦", '¦', false},
+		{";;", 0, true},
+		{"", 0, true},
+		{`"`, 0, true},
+		{"\n", 0, true},
+	}
+
+	for _, test := range table {
+		got, err := parseDelimiter(test.in)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseDelimiter(%q) error = %v, want error %v", test.in, err, test.wantErr)
+		}
+
+		if got != test.want {
+			t.Errorf("parseDelimiter(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestFormatCSVSemicolon(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "the cat the\n")
+
+	_, report := runCount(t, "--format=csv", "--delimiter=semicolon", input)
+
+	r := csv.NewReader(strings.NewReader(report))
+	r.Comma = ';'
+
+	rows, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("parsing csv: %v\n%s", err, report)
+	}
+
+	if want := []string{"file", "table", "column", "value", "count", "percent"}; !slices.Equal(rows[0], want) {
+		t.Errorf("header = %q, want %q", rows[0], want)
+	}
+
+	want := []string{"", "words", "raw", "the", "2", "66.67"}
+	if !slices.ContainsFunc(rows, func(row []string) bool { return slices.Equal(row, want) }) {
+		t.Errorf("csv is missing the row %q:\n%s", want, report)
+	}
+}
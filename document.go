@@ -0,0 +1,311 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/alcionai/clues/cluerr"
+	"github.com/ledongthuc/pdf"
+)
+
+const (
+	pdfExt  = ".pdf"
+	epubExt = ".epub"
+	docxExt = ".docx"
+)
+
+// documentExts holds the extensions of all document formats whose
+// text gets extracted before counting.
+var documentExts = []string{pdfExt, epubExt, docxExt}
+
+// isDocument is true if the path is a document format whose text
+// gets extracted before counting.
+func isDocument(p string) bool {
+	p = strings.ToLower(p)
+
+	for _, ext := range documentExts {
+		if strings.HasSuffix(p, ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// runDocument extracts the text of the document, and counts it.
+// Each document is counted as a single corpus.
+func (h *handler) runDocument(
+	ctx context.Context,
+	filePath string,
+) error {
+	if !isLocal(filePath) {
+		return cluerr.NewWC(ctx, "documents must be local files: "+filePath)
+	}
+
+	switch lower := strings.ToLower(filePath); {
+	case strings.HasSuffix(lower, epubExt):
+		return h.runEPUB(ctx, filePath)
+	case strings.HasSuffix(lower, docxExt):
+		return h.runDOCX(ctx, filePath)
+	}
+
+	return h.runPDF(ctx, filePath)
+}
+
+// runPDF counts the text layer of a pdf.  Pdfs without a text
+// layer (ex: scanned images) produce no text.
+func (h *handler) runPDF(
+	ctx context.Context,
+	filePath string,
+) error {
+	f, r, err := pdf.Open(filePath)
+	if err != nil {
+		return cluerr.WrapWC(ctx, err, "opening pdf: "+filePath)
+	}
+
+	defer f.Close()
+
+	text, err := r.GetPlainText()
+	if err != nil {
+		return cluerr.WrapWC(ctx, err, "extracting pdf text: "+filePath)
+	}
+
+	return h.runReader(ctx, filePath, text)
+}
+
+// runEPUB counts the prose of every document in the epub's spine,
+// in reading order.
+func (h *handler) runEPUB(
+	ctx context.Context,
+	filePath string,
+) error {
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return cluerr.WrapWC(ctx, err, "opening epub: "+filePath)
+	}
+
+	defer zr.Close()
+
+	spine, err := epubSpine(&zr.Reader)
+	if err != nil {
+		return cluerr.WrapWC(ctx, err, "reading epub spine: "+filePath)
+	}
+
+	text := epubText(&zr.Reader, spine)
+	defer text.Close()
+
+	return h.runReader(ctx, filePath, text)
+}
+
+// epubContainer is the META-INF/container.xml of an epub, which
+// locates its package document.
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// epubPackage is the package (.opf) document of an epub, which lists
+// its files, and the order in which they're read.
+type epubPackage struct {
+	Items []struct {
+		ID   string `xml:"id,attr"`
+		Href string `xml:"href,attr"`
+	} `xml:"manifest>item"`
+	Itemrefs []struct {
+		IDRef string `xml:"idref,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// epubSpine produces the paths, within the epub, of every document
+// in the spine.
+func epubSpine(zr *zip.Reader) ([]string, error) {
+	var container epubContainer
+
+	if err := decodeZipXML(zr, "META-INF/container.xml", &container); err != nil {
+		return nil, err
+	}
+
+	if len(container.Rootfiles) == 0 {
+		return nil, cluerr.New("epub container has no rootfile")
+	}
+
+	var (
+		opf = container.Rootfiles[0].FullPath
+		pkg epubPackage
+	)
+
+	if err := decodeZipXML(zr, opf, &pkg); err != nil {
+		return nil, err
+	}
+
+	hrefs := map[string]string{}
+
+	for _, item := range pkg.Items {
+		hrefs[item.ID] = item.Href
+	}
+
+	spine := make([]string, 0, len(pkg.Itemrefs))
+
+	for _, ref := range pkg.Itemrefs {
+		href, ok := hrefs[ref.IDRef]
+		if !ok {
+			continue
+		}
+
+		// hrefs are urls relative to the package document.
+		if unescaped, err := url.PathUnescape(href); err == nil {
+			href = unescaped
+		}
+
+		spine = append(spine, path.Join(path.Dir(opf), href))
+	}
+
+	return spine, nil
+}
+
+// decodeZipXML unmarshals the xml in the named zip member into v.
+func decodeZipXML(zr *zip.Reader, name string, v any) error {
+	f, err := zr.Open(name)
+	if err != nil {
+		return cluerr.Wrap(err, "opening "+name)
+	}
+
+	defer f.Close()
+
+	if err := xml.NewDecoder(f).Decode(v); err != nil {
+		return cluerr.Wrap(err, "parsing "+name)
+	}
+
+	return nil
+}
+
+// epubText produces the text of each of the spine's documents, in
+// order, with all markup removed.  Closing the result stops the
+// extraction.
+func epubText(zr *zip.Reader, spine []string) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		for _, name := range spine {
+			if err := writeZipHTMLText(zr, name, pw); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+
+		pw.Close()
+	}()
+
+	return pr
+}
+
+func writeZipHTMLText(zr *zip.Reader, name string, w io.Writer) error {
+	f, err := zr.Open(name)
+	if err != nil {
+		return cluerr.Wrap(err, "opening "+name)
+	}
+
+	defer f.Close()
+
+	if err := writeHTMLText(f, w); err != nil {
+		return err
+	}
+
+	// keeps the last word of one document apart from the first
+	// word of the next.
+	_, err = io.WriteString(w, "\n")
+
+	return err
+}
+
+// docxDocument is the member of a docx that holds the body text.
+const docxDocument = "word/document.xml"
+
+// runDOCX counts the body text of a docx.  Headers, footers, and
+// comments are kept in other members, and aren't counted.
+func (h *handler) runDOCX(
+	ctx context.Context,
+	filePath string,
+) error {
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return cluerr.WrapWC(ctx, err, "opening docx: "+filePath)
+	}
+
+	defer zr.Close()
+
+	f, err := zr.Open(docxDocument)
+	if err != nil {
+		return cluerr.WrapWC(ctx, err, "opening docx document: "+filePath)
+	}
+
+	defer f.Close()
+
+	text := docxText(f)
+	defer text.Close()
+
+	return h.runReader(ctx, filePath, text)
+}
+
+// docxText produces the text of the runs in a docx document.xml,
+// with each paragraph on its own line.  Deleted text, from tracked
+// changes, is dropped.  Closing the result stops the parsing.
+func docxText(r io.Reader) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		pw.CloseWithError(writeDOCXText(r, pw))
+	}()
+
+	return pr
+}
+
+func writeDOCXText(r io.Reader, w io.Writer) error {
+	var (
+		dec    = xml.NewDecoder(r)
+		bw     = bufio.NewWriter(w)
+		inText bool
+	)
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return bw.Flush()
+		}
+
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab", "br", "cr":
+				bw.WriteString(" ")
+			}
+
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				bw.WriteString("\n")
+			}
+
+		case xml.CharData:
+			if inText {
+				bw.Write(t)
+			}
+		}
+	}
+}
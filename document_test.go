@@ -0,0 +1,215 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testPDF produces a single page pdf whose text layer holds the text,
+// in the standard helvetica font.
+func testPDF(text string) []byte {
+	var (
+		buf     bytes.Buffer
+		offsets []int
+		content = fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+		objects = []string{
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] " +
+				"/Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+			"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		}
+	)
+
+	buf.WriteString("%PDF-1.4\n")
+
+	for i, obj := range objects {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes()
+}
+
+func TestPDFInput(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "doc.pdf", string(testPDF("portable document words")))
+
+	h, _ := runCount(t, input)
+
+	for _, word := range []string{"portable", "document", "words"} {
+		if got := countOf(h.words.universal, word); got != 1 {
+			t.Errorf("count of %s = %d, want 1", word, got)
+		}
+	}
+}
+
+func TestPDFInputErrors(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "doc.pdf", "not a pdf\n")
+
+	err := execCount(newHandler(), input)
+	if err == nil || !strings.Contains(err.Error(), "opening pdf") {
+		t.Errorf("error = %v, want a pdf error", err)
+	}
+}
+
+var testEPUBMembers = []archiveMember{
+	{"mimetype", "application/epub+zip"},
+	{"META-INF/container.xml", `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>`},
+	{"OEBPS/content.opf", `<?xml version="1.0"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml"/>
+    <item id="one" href="text/chapter%20one.xhtml" media-type="application/xhtml+xml"/>
+    <item id="two" href="text/two.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="two"/>
+    <itemref idref="one"/>
+    <itemref idref="missing"/>
+  </spine>
+</package>`},
+	{"OEBPS/nav.xhtml", `<html><body><nav>contents</nav></body></html>`},
+	{"OEBPS/text/chapter one.xhtml", `<html><head><title>first</title></head><body><p>opening prose</p></body></html>`},
+	{"OEBPS/text/two.xhtml", `<html><body><p>closing prose</p><script>var code;</script></body></html>`},
+}
+
+func TestEPUBSpine(t *testing.T) {
+	var buf bytes.Buffer
+
+	writeZip(t, &buf, testEPUBMembers)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("reading epub: %v", err)
+	}
+
+	spine, err := epubSpine(zr)
+	if err != nil {
+		t.Fatalf("reading spine: %v", err)
+	}
+
+	want := []string{"OEBPS/text/two.xhtml", "OEBPS/text/chapter one.xhtml"}
+	if !slices.Equal(spine, want) {
+		t.Errorf("spine = %q, want %q", spine, want)
+	}
+}
+
+func TestEPUBInput(t *testing.T) {
+	var buf bytes.Buffer
+
+	writeZip(t, &buf, testEPUBMembers)
+
+	input := writeInput(t, t.TempDir(), "book.epub", buf.String())
+
+	h, _ := runCount(t, input)
+
+	for word, want := range map[string]int64{
+		"opening":  1,
+		"closing":  1,
+		"prose":    2,
+		"contents": 0,
+		"code":     0,
+	} {
+		if got := countOf(h.words.universal, word); got != want {
+			t.Errorf("count of %s = %d, want %d", word, got, want)
+		}
+	}
+}
+
+func TestEPUBInputErrors(t *testing.T) {
+	var buf bytes.Buffer
+
+	writeZip(t, &buf, testEPUBMembers[3:])
+
+	input := writeInput(t, t.TempDir(), "book.epub", buf.String())
+
+	err := execCount(newHandler(), input)
+	if err == nil || !strings.Contains(err.Error(), "opening META-INF/container.xml") {
+		t.Errorf("error = %v, want a missing container error", err)
+	}
+}
+
+const testDOCXDocument = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:body>
+    <w:p><w:r><w:t>first</w:t></w:r><w:r><w:t xml:space="preserve"> paragraph</w:t></w:r></w:p>
+    <w:p><w:r><w:t>tabbed</w:t><w:tab/><w:t>apart</w:t></w:r></w:p>
+    <w:p><w:del><w:r><w:delText>deleted</w:delText></w:r></w:del><w:ins><w:r><w:t>inserted</w:t></w:r></w:ins></w:p>
+    <w:p><w:r><w:t>split</w:t></w:r></w:p><w:p><w:r><w:t>lines</w:t></w:r></w:p>
+  </w:body>
+</w:document>`
+
+func TestDOCXText(t *testing.T) {
+	text := docxText(strings.NewReader(testDOCXDocument))
+	defer text.Close()
+
+	got, err := io.ReadAll(text)
+	if err != nil {
+		t.Fatalf("reading docx text: %v", err)
+	}
+
+	want := "first paragraph\ntabbed apart\ninserted\nsplit\nlines\n"
+	if string(got) != want {
+		t.Errorf("docx text = %q, want %q", got, want)
+	}
+}
+
+func TestDOCXInput(t *testing.T) {
+	var buf bytes.Buffer
+
+	writeZip(t, &buf, []archiveMember{
+		{"[Content_Types].xml", `<Types/>`},
+		{docxDocument, testDOCXDocument},
+		{"word/header1.xml", `<w:hdr><w:p><w:r><w:t>header</w:t></w:r></w:p></w:hdr>`},
+	})
+
+	input := writeInput(t, t.TempDir(), "doc.docx", buf.String())
+
+	h, _ := runCount(t, input)
+
+	for word, want := range map[string]int64{
+		"first":    1,
+		"apart":    1,
+		"inserted": 1,
+		"deleted":  0,
+		"header":   0,
+	} {
+		if got := countOf(h.words.universal, word); got != want {
+			t.Errorf("count of %s = %d, want %d", word, got, want)
+		}
+	}
+}
+
+func TestDOCXInputErrors(t *testing.T) {
+	var buf bytes.Buffer
+
+	writeZip(t, &buf, []archiveMember{{"word/header1.xml", `<w:hdr/>`}})
+
+	input := writeInput(t, t.TempDir(), "doc.docx", buf.String())
+
+	err := execCount(newHandler(), input)
+	if err == nil || !strings.Contains(err.Error(), "opening docx document") {
+		t.Errorf("error = %v, want a missing document error", err)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"unicode/utf8"
+
+	"github.com/alcionai/clues/cluerr"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// sniffSize is the count of leading bytes inspected when
+// detecting the encoding of a file.
+const sniffSize = 4096
+
+type textEncoding string
+
+const (
+	encUTF8    textEncoding = "utf-8"
+	encUTF8BOM textEncoding = "utf-8-bom"
+	encLatin1  textEncoding = "latin-1"
+	encWin1252 textEncoding = "windows-1252"
+	encUTF16LE textEncoding = "utf-16le"
+	encUTF16BE textEncoding = "utf-16be"
+)
+
+var decoders = map[textEncoding]encoding.Encoding{
+	encUTF8:    unicode.UTF8,
+	encUTF8BOM: unicode.UTF8BOM,
+	encLatin1:  charmap.ISO8859_1,
+	encWin1252: charmap.Windows1252,
+	// UseBOM strips the byte order mark when present, and falls back
+	// to the given endianness when it isn't.
+	encUTF16LE: unicode.UTF16(unicode.LittleEndian, unicode.UseBOM),
+	encUTF16BE: unicode.UTF16(unicode.BigEndian, unicode.UseBOM),
+}
+
+// minUTF16NulRatio is the share of code units that must have a nul
+// high byte for bom-less text to be considered utf-16.  Mostly-ascii
+// utf-16 text has a nul in nearly every code unit, while other text
+// encodings very rarely contain nuls at all.
+const minUTF16NulRatio = 0.3
+
+// decodeReader detects the encoding of the text in r, and returns
+// a reader that produces that text as utf-8.  Detection happens
+// separately for every reader, so inputs in mixed encodings each
+// get decoded correctly.
+func decodeReader(r io.Reader) (io.Reader, textEncoding, error) {
+	br := bufio.NewReaderSize(r, sniffSize)
+
+	head, err := br.Peek(sniffSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, "", cluerr.Wrap(err, "sniffing text encoding")
+	}
+
+	enc := detectEncoding(head, len(head) == sniffSize)
+
+	return decoders[enc].NewDecoder().Reader(br), enc, nil
+}
+
+// detectEncoding guesses the encoding of the leading bytes of some
+// text.  A byte order mark is always trusted.  Without one, text with
+// nuls in alternating bytes is assumed to be utf-16.  Otherwise,
+// anything that isn't valid utf-8 is assumed to be windows-1252 if
+// it uses any of the characters windows-1252 places in the latin-1
+// control range (ex: curly quotes), or else latin-1.  Both can decode
+// any byte sequence.
+//
+// truncated should be true if head was cut off from a larger
+// input, in which case a trailing partial rune is acceptable.
+func detectEncoding(head []byte, truncated bool) textEncoding {
+	if len(head) >= 3 &&
+		head[0] == 0xEF &&
+		head[1] == 0xBB &&
+		head[2] == 0xBF {
+		return encUTF8BOM
+	}
+
+	if len(head) >= 2 && head[0] == 0xFF && head[1] == 0xFE {
+		return encUTF16LE
+	}
+
+	if len(head) >= 2 && head[0] == 0xFE && head[1] == 0xFF {
+		return encUTF16BE
+	}
+
+	if enc, ok := detectUTF16(head); ok {
+		return enc
+	}
+
+	if truncated {
+		head = trimPartialRune(head)
+	}
+
+	if utf8.Valid(head) {
+		return encUTF8
+	}
+
+	if hasC1Controls(head) {
+		return encWin1252
+	}
+
+	return encLatin1
+}
+
+// detectUTF16 guesses whether bom-less text is utf-16, and of which
+// endianness, by where its nul bytes fall.
+func detectUTF16(head []byte) (textEncoding, bool) {
+	units := len(head) / 2
+	if units == 0 {
+		return "", false
+	}
+
+	var evenNuls, oddNuls int
+
+	for i := 0; i+1 < len(head); i += 2 {
+		if head[i] == 0 {
+			evenNuls++
+		}
+
+		if head[i+1] == 0 {
+			oddNuls++
+		}
+	}
+
+	threshold := int(float64(units) * minUTF16NulRatio)
+
+	switch {
+	case oddNuls > threshold && evenNuls <= threshold/10:
+		return encUTF16LE, true
+	case evenNuls > threshold && oddNuls <= threshold/10:
+		return encUTF16BE, true
+	}
+
+	return "", false
+}
+
+// hasC1Controls is true if b contains any byte in 0x80-0x9F.  Those
+// are unprintable controls in latin-1, but punctuation and letters in
+// windows-1252, so their presence suggests windows-1252 text.
+func hasC1Controls(b []byte) bool {
+	for _, c := range b {
+		if c >= 0x80 && c <= 0x9F {
+			return true
+		}
+	}
+
+	return false
+}
+
+// trimPartialRune drops an incomplete utf-8 sequence from the end of b.
+func trimPartialRune(b []byte) []byte {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return b[:i]
+			}
+
+			break
+		}
+	}
+
+	return b
+}
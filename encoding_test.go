@@ -0,0 +1,130 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	table := []struct {
+		name string
+		head []byte
+		want textEncoding
+	}{
+		{"ascii", []byte("plain text"), encUTF8},
+		{"utf-8", []byte("caf\xc3\xa9"), encUTF8},
+		{"utf-8 bom", []byte("\xef\xbb\xbfcaf\xc3\xa9"), encUTF8BOM},
+		{"latin-1", []byte("caf\xe9"), encLatin1},
+		{"windows-1252", []byte("\x93quoted\x94"), encWin1252},
+		{"utf-16le bom", []byte("\xff\xfeh\x00i\x00"), encUTF16LE},
+		{"utf-16be", []byte("\x00h\x00e\x00l\x00l\x00o"), encUTF16BE},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			if got := detectEncoding(test.head, false); got != test.want {
+				t.Errorf("detected %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDetectEncodingTruncated(t *testing.T) {
+	// the head was cut off partway through é.
+	head := []byte("caf\xc3")
+
+	if got := detectEncoding(head, true); got != encUTF8 {
+		t.Errorf("detected %s, want %s", got, encUTF8)
+	}
+}
+
+func TestCountMixedEncodings(t *testing.T) {
+	dir := t.TempDir()
+
+	writeInput(t, dir, "utf8.txt", "café\n")
+	writeInput(t, dir, "latin1.txt", "caf\xe9\n")
+
+	h, _ := runCount(t, dir)
+
+	if got := countOf(h.words.universal, "café"); got != 2 {
+		t.Errorf("count of café = %d, want 2", got)
+	}
+
+	if got := countOf(h.letters.universal, "é"); got != 2 {
+		t.Errorf("count of é = %d, want 2", got)
+	}
+}
+
+// utf16 encodes the ascii text as utf-16, in either byte order.
+func utf16(text string, bigEndian bool) string {
+	var b strings.Builder
+
+	for i := range len(text) {
+		if bigEndian {
+			b.WriteByte(0)
+			b.WriteByte(text[i])
+		} else {
+			b.WriteByte(text[i])
+			b.WriteByte(0)
+		}
+	}
+
+	return b.String()
+}
+
+func TestDecodeReader(t *testing.T) {
+	table := []struct {
+		name, text, want string
+		wantEnc          textEncoding
+	}{
+		{"utf-8", "café\n", "café\n", encUTF8},
+		{"utf-8 bom", "\xef\xbb\xbfcafé\n", "café\n", encUTF8BOM},
+		{"latin-1", "caf\xe9\n", "café\n", encLatin1},
+		{"windows-1252", "\x93smart\x94 quotes\n", "“smart” quotes\n", encWin1252},
+		{"utf-16le bom", "\xff\xfe" + utf16("hello\n", false), "hello\n", encUTF16LE},
+		{"utf-16be bom", "\xfe\xff" + utf16("hello\n", true), "hello\n", encUTF16BE},
+		{"utf-16le", utf16("hello there\n", false), "hello there\n", encUTF16LE},
+		{"utf-16be", utf16("hello there\n", true), "hello there\n", encUTF16BE},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			r, enc, err := decodeReader(strings.NewReader(test.text))
+			if err != nil {
+				t.Fatalf("decoding: %v", err)
+			}
+
+			if enc != test.wantEnc {
+				t.Errorf("detected %s, want %s", enc, test.wantEnc)
+			}
+
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("reading: %v", err)
+			}
+
+			if string(got) != test.want {
+				t.Errorf("decoded %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCountTranscodedInputs(t *testing.T) {
+	dir := t.TempDir()
+
+	writeInput(t, dir, "le.txt", "\xff\xfe"+utf16("hello\n", false))
+	writeInput(t, dir, "be.txt", utf16("hello world\n", true))
+	writeInput(t, dir, "win.txt", "hello caf\xe9\x85\n")
+
+	h, _ := runCount(t, dir)
+
+	if got := countOf(h.words.universal, "hello"); got != 3 {
+		t.Errorf("count of hello = %d, want 3", got)
+	}
+
+	if got := countOf(h.words.universal, "café"); got != 1 {
+		t.Errorf("count of café = %d, want 1", got)
+	}
+}
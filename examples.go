@@ -0,0 +1,107 @@
+package main
+
+import (
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// recipe is a single example invocation of count.
+type recipe struct {
+	about string
+	cmd   string
+}
+
+// recipes are the examples listed by both the examples command
+// and the root help.  Keep each about to a single line.
+var recipes = []recipe{
+	{
+		about: "count the words and letters of a book",
+		cmd:   "count ~/corpus/alice_in_wonderland.txt",
+	},
+	{
+		about: "swap th for ð, and ignore every \"the\"",
+		cmd:   "count --swapNgram=th,ð --removeWord=the ~/corpus/alice_in_wonderland.txt",
+	},
+	{
+		about: "show the top 10 and top 100 words as separate tables",
+		cmd:   "count --top-words=10,100 ~/corpus/*.txt",
+	},
+	{
+		about: "report each file separately, alongside the aggregate",
+		cmd:   "count --per-file ~/corpus/*.txt",
+	},
+	{
+		about: "weight every file equally, regardless of its length",
+		cmd:   "count --normalize-per-file ~/corpus/*.txt",
+	},
+	{
+		about: "write the results as json, for other tools to read",
+		cmd:   "count --format=json -o=results.json ~/corpus/*.txt",
+	},
+	{
+		about: "write the results as tab separated values",
+		cmd:   "count --format=csv --delimiter=tab ~/corpus/*.txt",
+	},
+	{
+		about: "strip html elements from scraped pages",
+		cmd:   "count --removeHTML ~/corpus/scraped.txt",
+	},
+	{
+		about: "save counts from one run, and add onto them in another",
+		cmd:   "count --save-state=jan.json.gz jan.txt && count --load-state=jan.json.gz feb.txt",
+	},
+	{
+		about: "compare the top word ranks between two saved states",
+		cmd:   "count rank-diff --top=20 jan.json.gz feb.json.gz",
+	},
+	{
+		about: "fail if letter frequencies drift from a baseline",
+		cmd:   "count --save-baseline-json=base.json ~/corpus/*.txt && count --diff-against-baseline-json=base.json --tolerance=0.5 ~/corpus/*.txt",
+	},
+	{
+		about: "produce a trigram profile for language identification",
+		cmd:   "count --trigram-profile=en.json ~/corpus/english/*.txt",
+	},
+}
+
+func newExamples() *cobra.Command {
+	return &cobra.Command{
+		Use:   "examples",
+		Short: "print example invocations of count",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			writeRecipes(cmd.OutOrStdout())
+		},
+	}
+}
+
+// writeRecipes writes each recipe as a comment line explaining it,
+// followed by the command.
+func writeRecipes(w io.Writer) {
+	for i, r := range recipes {
+		if i > 0 {
+			writeLn(w, "")
+		}
+
+		writeLn(w, "# "+r.about)
+		writeLn(w, r.cmd)
+	}
+}
+
+// recipesExample formats the recipes for use as a cobra Example.
+func recipesExample() string {
+	var sb strings.Builder
+
+	writeRecipes(&sb)
+
+	lines := strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+	for i, ln := range lines {
+		if len(ln) > 0 {
+			lines[i] = "  " + ln
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExamples(t *testing.T) {
+	out := runSubcommand(t, "examples")
+
+	for _, r := range recipes {
+		if !strings.Contains(out, "# "+r.about+"\n"+r.cmd+"\n") {
+			t.Errorf("examples are missing the recipe %q:\n%s", r.about, out)
+		}
+	}
+
+	if !strings.Contains(out, "count --top-words=10,100 ~/corpus/*.txt") {
+		t.Errorf("examples are missing the top words recipe:\n%s", out)
+	}
+}
+
+// every flag used by a recipe is a real flag of the command it runs.
+func TestRecipeFlags(t *testing.T) {
+	root := newRoot(newHandler())
+
+	for _, r := range recipes {
+		for _, invocation := range strings.Split(r.cmd, "&&") {
+			args := strings.Fields(invocation)[1:]
+
+			cmd, args, err := root.Find(args)
+			if err != nil {
+				t.Errorf("recipe %q: finding command: %v", r.about, err)
+				continue
+			}
+
+			for _, arg := range args {
+				name, ok := strings.CutPrefix(arg, "--")
+				if !ok {
+					continue
+				}
+
+				name, _, _ = strings.Cut(name, "=")
+
+				if cmd.Flags().Lookup(name) == nil && cmd.InheritedFlags().Lookup(name) == nil {
+					t.Errorf("recipe %q uses unknown flag --%s", r.about, name)
+				}
+			}
+		}
+	}
+}
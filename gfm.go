@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// writeGFM writes the tables as github flavored markdown, with each
+// section collapsed into a details block summarized by its title.
+func (h *handler) writeGFM(w io.Writer) {
+	for i, sec := range h.tableSections() {
+		if i > 0 {
+			writeLn(w, "")
+		}
+
+		var buf bytes.Buffer
+
+		sec.table(&buf)
+
+		title, table, _ := strings.Cut(buf.String(), "\n")
+
+		writeLn(w, "<details>")
+		writeLn(w, "<summary>"+title+"</summary>")
+		// github only renders markdown within html blocks
+		// when separated by a blank line.
+		writeLn(w, "")
+		writeLn(w, strings.TrimSuffix(table, "\n"))
+
+		// footers directly below a table render as more of its rows,
+		// and consecutive footers render as a single paragraph.
+		for _, footer := range sec.footers {
+			writeLn(w, "")
+			footer(w)
+		}
+
+		writeLn(w, "")
+		writeLn(w, "</details>")
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatGFM(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "b a a\n")
+
+	_, report := runCount(t, "--format=gfm", input)
+
+	want := strings.Join([]string{
+		"<details>",
+		"<summary>words</summary>",
+		"",
+		"| # | raw (3) | removed (3) | swapped (3) | both (3) |",
+		"|---|---|---|---|---|",
+		"|  0 |     a (     2, 66.67%) |     a (     2, 66.67%) |     a (     2, 66.67%) |     a (     2, 66.67%) |",
+		"|  1 |     b (     1, 33.33%) |     b (     1, 33.33%) |     b (     1, 33.33%) |     b (     1, 33.33%) |",
+		"",
+		"</details>",
+		"",
+		"<details>",
+		"<summary>letters</summary>",
+		"",
+		"| # | raw (3) | removed (3) | swapped (3) | both (3) |",
+		"|---|---|---|---|---|",
+		"|  0 |     a (     2, 66.67%) |     a (     2, 66.67%) |     a (     2, 66.67%) |     a (     2, 66.67%) |",
+		"|  1 |     b (     1, 33.33%) |     b (     1, 33.33%) |     b (     1, 33.33%) |     b (     1, 33.33%) |",
+		"",
+		"</details>",
+		"",
+	}, "\n")
+
+	if report != want {
+		t.Errorf("report:\n%s\nwant:\n%s", report, want)
+	}
+}
+
+func TestFormatGFMSections(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "hello see\n")
+
+	_, report := runCount(t, "--format=gfm", "--geminates", "--words-per-line", input)
+
+	for _, title := range []string{"words", "letters", "words per line", "geminates"} {
+		if !strings.Contains(report, "<details>\n<summary>"+title+"</summary>\n\n|") {
+			t.Errorf("section %q isn't wrapped in a details block:\n%s", title, report)
+		}
+	}
+
+	if opened, closed := strings.Count(report, "<details>"), strings.Count(report, "</details>"); opened != 4 || closed != 4 {
+		t.Errorf("report has %d opened and %d closed details blocks, want 4 of each", opened, closed)
+	}
+}
+
+func TestFormatGFMFooters(t *testing.T) {
+	var (
+		dir = t.TempDir()
+		a   = writeInput(t, dir, "a.txt", "the cat\n")
+		b   = writeInput(t, dir, "b.txt", "the dog\n")
+	)
+
+	_, report := runCount(
+		t,
+		"--format=gfm",
+		"--stats",
+		"--longest-common-word",
+		"--swap-ratio",
+		"-s=th,ð",
+		a, b,
+	)
+
+	// a blank line separates each footer from the table, and from
+	// the footer before it.
+	for _, want := range []string{
+		"|\n\nmean word length: 3.00, median word length: 3.0\n\n" +
+			"longest common word: the (3 letters, in all 2 files)\n\n</details>\n",
+		"|\n\nletters changed by swaps: 4 of 12 (33.33%)\n\n</details>\n",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing the footers %q:\n%s", want, report)
+		}
+	}
+
+	if opened := strings.Count(report, "<details>"); opened != 2 {
+		t.Errorf("report has %d details blocks, want 2:\n%s", opened, report)
+	}
+}
@@ -6,9 +6,18 @@ toolchain go1.24.2
 
 require (
 	github.com/alcionai/clues v0.0.0-20250404152412-611c8b8e1eb5
+	github.com/bmatcuk/doublestar/v4 v4.10.2
+	github.com/fsnotify/fsnotify v1.9.0
+	github.com/klauspost/compress v1.18.0
+	github.com/ledongthuc/pdf v0.0.0-20240201131950-da5b75280b06
 	github.com/pawelszydlo/humanize v0.0.0-20200522003854-142c3fe71478
 	github.com/puzpuzpuz/xsync/v4 v4.0.0
+	github.com/rivo/uniseg v0.4.7
 	github.com/spf13/cobra v1.9.1
+	go.uber.org/zap v1.27.0
+	golang.org/x/net v0.36.0
+	golang.org/x/text v0.22.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -35,11 +44,8 @@ require (
 	go.opentelemetry.io/otel/trace v1.35.0 // indirect
 	go.opentelemetry.io/proto/otlp v1.5.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
-	go.uber.org/zap v1.27.0 // indirect
 	golang.org/x/exp v0.0.0-20240416160154-fe59bbe5cc7f // indirect
-	golang.org/x/net v0.36.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
-	golang.org/x/text v0.22.0 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20250218202821-56aae31c358a // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250218202821-56aae31c358a // indirect
 	google.golang.org/grpc v1.71.1 // indirect
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"github.com/rivo/uniseg"
+)
+
+// emojiKeepChars is the regex character class of the emoji, and the
+// joiners and selectors within emoji sequences, kept by normalization
+// when emoji get counted.  Combining marks (ex: the keycap of 1️⃣) are
+// always kept.
+const emojiKeepChars = `\p{So}\p{Sk}\x{200D}\x{FE0F}`
+
+// graphemes splits the word into its user-perceived characters, so
+// that combining sequences (ex: e+◌́), flags, and joined emoji (ex:
+// 👩‍👩‍👧) are each one letter, rather than one letter per rune.
+func graphemes(word string) []string {
+	var (
+		clusters = make([]string, 0, len(word))
+		cluster  string
+		state    = -1
+	)
+
+	for len(word) > 0 {
+		cluster, word, _, state = uniseg.FirstGraphemeClusterInString(word, state)
+		clusters = append(clusters, cluster)
+	}
+
+	return clusters
+}
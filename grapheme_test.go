@@ -0,0 +1,51 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGraphemes(t *testing.T) {
+	table := []struct {
+		name string
+		word string
+		want []string
+	}{
+		{"ascii", "abc", []string{"a", "b", "c"}},
+		{"decomposed accent", "cafe\u0301", []string{"c", "a", "f", "e\u0301"}},
+		{"flag", "🇫🇷ok", []string{"🇫🇷", "o", "k"}},
+		{"joined emoji", "👩‍👩‍👧", []string{"👩‍👩‍👧"}},
+		{"skin tone", "👍🏽", []string{"👍🏽"}},
+		{"empty", "", []string{}},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			if got := graphemes(test.word); !slices.Equal(got, test.want) {
+				t.Errorf("graphemes(%q) = %q, want %q", test.word, got, test.want)
+			}
+		})
+	}
+}
+
+func TestCountGraphemes(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "cafe\u0301 👍🏽 👍🏽 🇫🇷\n")
+
+	h, _ := runCount(t, "--emoji", input)
+
+	for letter, want := range map[string]int64{"e\u0301": 1, "e": 0, "👍🏽": 2, "🇫🇷": 1, "🏽": 0} {
+		if got := countOf(h.letters.universal, letter); got != want {
+			t.Errorf("count of %q = %d, want %d", letter, got, want)
+		}
+	}
+
+	h, _ = runCount(t, input)
+
+	if got := countOf(h.letters.universal, "👍🏽"); got != 0 {
+		t.Errorf("count of 👍🏽 without --emoji = %d, want 0", got)
+	}
+
+	if got := countOf(h.letters.universal, "e\u0301"); got != 1 {
+		t.Errorf("count of e\u0301 without --emoji = %d, want 1", got)
+	}
+}
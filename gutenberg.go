@@ -0,0 +1,44 @@
+package main
+
+import (
+	"regexp"
+)
+
+var (
+	gutenbergStartRE = regexp.MustCompile(`(?i)^\s*\*\*\*\s*START OF (THE|THIS) PROJECT GUTENBERG`)
+	gutenbergEndRE   = regexp.MustCompile(`(?i)^\s*\*\*\*\s*END OF (THE|THIS) PROJECT GUTENBERG`)
+)
+
+// gutenbergState tracks where a line falls relative to the project
+// gutenberg start and end markers of a single file.
+type gutenbergState int
+
+const (
+	gutenbergHeader gutenbergState = iota
+	gutenbergBody
+	gutenbergFooter
+)
+
+// next advances the state past the line, and reports whether the line
+// falls within the body of the text.  The marker lines themselves are
+// never part of the body.
+func (gs *gutenbergState) next(ln string) bool {
+	switch *gs {
+	case gutenbergHeader:
+		if gutenbergStartRE.MatchString(ln) {
+			*gs = gutenbergBody
+		}
+
+		return false
+
+	case gutenbergBody:
+		if gutenbergEndRE.MatchString(ln) {
+			*gs = gutenbergFooter
+			return false
+		}
+
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testGutenberg = `The Project Gutenberg eBook of Example
+license header
+*** START OF THE PROJECT GUTENBERG EBOOK EXAMPLE ***
+first line of the book
+last line of the book
+*** END OF THE PROJECT GUTENBERG EBOOK EXAMPLE ***
+license footer
+*** START OF THIS PROJECT GUTENBERG EBOOK AGAIN ***
+after the footer`
+
+func TestGutenbergState(t *testing.T) {
+	table := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "markers",
+			text: testGutenberg,
+			want: []string{"first line of the book", "last line of the book"},
+		},
+		{
+			name: "older markers",
+			text: "header\n  ***START OF THIS PROJECT GUTENBERG EBOOK***\nbody\n*** end of this project gutenberg ebook ***\nfooter",
+			want: []string{"body"},
+		},
+		{
+			name: "no end marker",
+			text: "header\n*** START OF THE PROJECT GUTENBERG EBOOK ***\nbody\nmore body",
+			want: []string{"body", "more body"},
+		},
+		{
+			name: "no markers",
+			text: "not a gutenberg text\nat all",
+			want: []string{},
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				gs   gutenbergState
+				body = []string{}
+			)
+
+			for _, ln := range strings.Split(test.text, "\n") {
+				if gs.next(ln) {
+					body = append(body, ln)
+				}
+			}
+
+			if !slices.Equal(body, test.want) {
+				t.Errorf("body = %q, want %q", body, test.want)
+			}
+		})
+	}
+}
+
+func TestStripGutenberg(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "book.txt", testGutenberg+"\n")
+
+	h, _ := runCount(t, "--strip-gutenberg", input)
+
+	for word, want := range map[string]int64{
+		"book":    2,
+		"license": 0,
+		"footer":  0,
+		"after":   0,
+	} {
+		if got := countOf(h.words.universal, word); got != want {
+			t.Errorf("count of %s = %d, want %d", word, got, want)
+		}
+	}
+}
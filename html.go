@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// inlineElements don't break up words, so no space gets added
+// around them (ex: <b>bold</b>er).
+var inlineElements = map[atom.Atom]struct{}{
+	atom.A:      {},
+	atom.Abbr:   {},
+	atom.B:      {},
+	atom.Em:     {},
+	atom.I:      {},
+	atom.Mark:   {},
+	atom.S:      {},
+	atom.Small:  {},
+	atom.Span:   {},
+	atom.Strong: {},
+	atom.Sub:    {},
+	atom.Sup:    {},
+	atom.U:      {},
+}
+
+// hiddenElements hold content that never gets displayed as page
+// text.  Titles only appear in window tabs and the like.
+var hiddenElements = map[atom.Atom]struct{}{
+	atom.Script:   {},
+	atom.Style:    {},
+	atom.Template: {},
+	atom.Noscript: {},
+	atom.Title:    {},
+}
+
+// htmlText produces only the text nodes of the html read from r,
+// with entities decoded.  The contents of scripts and styles are
+// dropped, and elements that aren't inline are replaced with a
+// space, so that text in neighboring elements stays separate.
+// Closing the result stops the parsing.
+func htmlText(r io.Reader) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		pw.CloseWithError(writeHTMLText(r, pw))
+	}()
+
+	return pr
+}
+
+func writeHTMLText(r io.Reader, w io.Writer) error {
+	var (
+		z      = html.NewTokenizer(r)
+		bw     = bufio.NewWriter(w)
+		hidden int
+	)
+
+	for {
+		tt := z.Next()
+
+		switch tt {
+		case html.ErrorToken:
+			if err := z.Err(); !errors.Is(err, io.EOF) {
+				return err
+			}
+
+			return bw.Flush()
+
+		case html.TextToken:
+			if hidden == 0 {
+				bw.Write(z.Text())
+			}
+
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			a := atom.Lookup(name)
+
+			if _, ok := hiddenElements[a]; ok {
+				switch tt {
+				case html.StartTagToken:
+					hidden++
+				case html.EndTagToken:
+					hidden = max(hidden-1, 0)
+				}
+			}
+
+			if _, ok := inlineElements[a]; !ok {
+				bw.WriteString(" ")
+			}
+		}
+	}
+}
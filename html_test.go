@@ -0,0 +1,69 @@
+package main
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHTMLText(t *testing.T) {
+	table := []struct {
+		name, html string
+		want       []string
+	}{
+		{"text nodes", "<p>first</p><p>second</p>", []string{"first", "second"}},
+		{"inline elements", "<p><b>bold</b>er and <a href='x'>link</a>ed</p>", []string{"bolder", "and", "linked"}},
+		{"attributes", `<div class="a > b" data-x='<p>'>content</div>`, []string{"content"}},
+		{"multi-line tags", "<img\n  src=\"a.png\"\n  alt=\"picture\"\n/>after", []string{"after"}},
+		{"entities", "caf&eacute; &amp; cr&#232;me", []string{"café", "&", "crème"}},
+		{"scripts and styles", "<script>var x = 1 < 2;</script><style>p { color: red }</style>shown", []string{"shown"}},
+		{"title", "<head><title>tab title</title></head><body>page</body>", []string{"page"}},
+		{"comments", "before<!-- a comment -->after <!-- another --> end", []string{"beforeafter", "end"}},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			text := htmlText(strings.NewReader(test.html))
+			defer text.Close()
+
+			got, err := io.ReadAll(text)
+			if err != nil {
+				t.Fatalf("reading text: %v", err)
+			}
+
+			if words := strings.Fields(string(got)); !slices.Equal(words, test.want) {
+				t.Errorf("text = %q, want %q", words, test.want)
+			}
+		})
+	}
+}
+
+func TestCountRemoveHTML(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "page.txt", `<html>
+<head><style>body { margin: 0 }</style></head>
+<body>
+<p class="intro"
+   id="first">hello
+<em>there</em></p>
+<script>var hidden = "not counted";</script>
+</body>
+</html>
+`)
+
+	h, _ := runCount(t, "--removeHTML", input)
+
+	for word, want := range map[string]int64{
+		"hello":   1,
+		"there":   1,
+		"class":   0,
+		"intro":   0,
+		"margin":  0,
+		"hidden":  0,
+		"counted": 0,
+	} {
+		if got := countOf(h.words.universal, word); got != want {
+			t.Errorf("count of %s = %d, want %d", word, got, want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// hyphenMode decides what happens to the hyphens within words.
+type hyphenMode string
+
+const (
+	// joins the parts of a hyphenated word (ex: wellknown).
+	hyphensJoin hyphenMode = "join"
+	// splits a hyphenated word into its parts (ex: well, known).
+	hyphensSplit hyphenMode = "split"
+	// keeps the hyphenated word as it is (ex: well-known).
+	hyphensKeep hyphenMode = "keep"
+)
+
+// hyphenModes holds all supported hyphen modes.
+var hyphenModes = []hyphenMode{
+	hyphensJoin,
+	hyphensSplit,
+	hyphensKeep,
+}
+
+func hyphenModeNames() []string {
+	names := make([]string, 0, len(hyphenModes))
+
+	for _, m := range hyphenModes {
+		names = append(names, string(m))
+	}
+
+	return names
+}
+
+// hyphenate applies the mode to every hyphen between two letters or
+// digits.  All other hyphens (ex: dashes, and hyphens breaking a word
+// across lines) become spaces when keeping hyphens, so that only the
+// hyphens within words survive the keep-chars pass.  Joining is left
+// to the keep-chars pass, which strips hyphens.  An empty mode joins.
+func hyphenate(ln string, mode hyphenMode) string {
+	if mode == hyphensJoin || len(mode) == 0 || !strings.Contains(ln, "-") {
+		return ln
+	}
+
+	var (
+		sb    strings.Builder
+		runes = []rune(ln)
+	)
+
+	sb.Grow(len(ln))
+
+	for i, r := range runes {
+		if r != '-' {
+			sb.WriteRune(r)
+			continue
+		}
+
+		inWord := i > 0 &&
+			i < len(runes)-1 &&
+			isAlnum(runes[i-1]) &&
+			isAlnum(runes[i+1])
+
+		switch {
+		case inWord && mode == hyphensKeep:
+			sb.WriteRune('-')
+		case inWord, mode == hyphensKeep:
+			sb.WriteRune(' ')
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
+
+func isAlnum(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
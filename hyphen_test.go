@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHyphenate(t *testing.T) {
+	const ln = "a well-known fact - or 2-3 -dashes- mid-"
+
+	table := []struct {
+		name string
+		mode hyphenMode
+		want string
+	}{
+		{"join", hyphensJoin, ln},
+		{"unset", "", ln},
+		{"split", hyphensSplit, "a well known fact - or 2 3 -dashes- mid-"},
+		{"keep", hyphensKeep, "a well-known fact   or 2-3  dashes  mid "},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			if got := hyphenate(ln, test.mode); got != test.want {
+				t.Errorf("hyphenate(%q) = %q, want %q", ln, got, test.want)
+			}
+		})
+	}
+}
+
+func TestHyphensInput(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "a well-known fact - mostly\n")
+
+	table := []struct {
+		mode string
+		want map[string]int64
+	}{
+		{"join", map[string]int64{"wellknown": 1, "well": 0, "well-known": 0}},
+		{"split", map[string]int64{"wellknown": 0, "well": 1, "known": 1, "well-known": 0}},
+		{"keep", map[string]int64{"wellknown": 0, "well": 0, "well-known": 1, "-": 0}},
+	}
+
+	for _, test := range table {
+		t.Run(test.mode, func(t *testing.T) {
+			h, _ := runCount(t, "--hyphens="+test.mode, input)
+
+			for word, want := range test.want {
+				if got := countOf(h.words.universal, word); got != want {
+					t.Errorf("count of %q = %d, want %d", word, got, want)
+				}
+			}
+		})
+	}
+
+	err := execCount(newHandler(), "--hyphens=hyphenate", input)
+	if err == nil || !strings.Contains(err.Error(), "unsupported hyphens mode") {
+		t.Errorf("expected an unsupported mode error, got %v", err)
+	}
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/alcionai/clues/cluerr"
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// stdinPath is the argument that reads the corpus from stdin.
+const stdinPath = "-"
+
+// openInput opens the input at path for reading.  Compressed inputs
+// are decompressed as they're read.
+func openInput(ctx context.Context, path string) (io.ReadCloser, error) {
+	if path == stdinPath {
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	var (
+		rc  io.ReadCloser
+		err error
+	)
+
+	switch {
+	case isObject(path):
+		rc, err = openURL(ctx, objectURL(path))
+	case isURL(path):
+		rc, err = openURL(ctx, path)
+	default:
+		rc, err = os.Open(path)
+	}
+
+	if err != nil {
+		return nil, cluerr.Wrap(err, "opening file")
+	}
+
+	return decompress(path, rc)
+}
+
+// inputMatcher decides which files get counted.
+type inputMatcher struct {
+	// the extensions of files to count, including the leading dot.
+	exts []string
+	// counts every file, regardless of its extension.
+	anyFile bool
+	// globs of files to skip, matched against both the full path
+	// and the file name.
+	exclude []string
+}
+
+// excluded is true if the path matches any exclude glob.
+func (m inputMatcher) excluded(path string) bool {
+	path = filepath.ToSlash(path)
+
+	for _, pattern := range m.exclude {
+		if doublestar.MatchUnvalidated(pattern, path) ||
+			doublestar.MatchUnvalidated(pattern, filepath.Base(path)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// counts is true if the file at path should be counted.  Compression
+// extensions are ignored (ex: book.txt.gz counts as book.txt).
+// Excluded files are never counted.
+func (m inputMatcher) counts(path string) bool {
+	if m.excluded(path) {
+		return false
+	}
+
+	if m.anyFile {
+		return true
+	}
+
+	path = trimCompressedExt(path)
+
+	for _, ext := range m.exts {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolveInputs checks every argument, and expands globs and
+// directories into all of the counted files they match or contain.
+// A glob's matches, and the files within a directory, are produced
+// in lexical order.
+func (m inputMatcher) resolveInputs(args []string) ([]string, error) {
+	paths := []string{}
+
+	for _, arg := range args {
+		if !isLocal(arg) {
+			paths = append(paths, arg)
+			continue
+		}
+
+		if !isGlob(arg) {
+			found, err := m.resolvePath(arg)
+			if err != nil {
+				return nil, err
+			}
+
+			paths = append(paths, found...)
+
+			continue
+		}
+
+		matches, err := doublestar.FilepathGlob(arg, doublestar.WithFilesOnly())
+		if err != nil {
+			return nil, cluerr.Wrap(err, "expanding glob: "+arg)
+		}
+
+		// as with directories, matches that aren't counted get skipped.
+		matches = slices.DeleteFunc(matches, func(match string) bool {
+			return !m.counts(match)
+		})
+
+		if len(matches) == 0 {
+			return nil, cluerr.New("no countable files match glob: " + arg)
+		}
+
+		slices.Sort(matches)
+
+		paths = append(paths, matches...)
+	}
+
+	return paths, nil
+}
+
+// resolvePath checks the path, and expands it into all counted files
+// within it, recursively, if it is a directory.
+func (m inputMatcher) resolvePath(path string) ([]string, error) {
+	if m.excluded(path) {
+		return nil, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, cluerr.Wrap(err, "checking file: "+path)
+	}
+
+	if info.IsDir() {
+		return m.walkDir(path)
+	}
+
+	if !m.counts(path) && !isArchive(path) && !isDocument(path) && !isSubtitle(path) && !isMbox(path) {
+		return nil, cluerr.New("must have a counted extension, or be an archive, document, subtitle, or mbox: "+path).
+			With("extensions", m.exts)
+	}
+
+	return []string{path}, nil
+}
+
+// isGlob is true if the argument contains any glob syntax.
+// Supports ** to match any count of directories.
+func isGlob(arg string) bool {
+	return strings.ContainsAny(arg, "*?[{")
+}
+
+// walkDir produces every counted file within the directory, recursively.
+func (m inputMatcher) walkDir(dir string) ([]string, error) {
+	paths := []string{}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() && path != dir && m.excluded(path) {
+			return filepath.SkipDir
+		}
+
+		if !d.IsDir() && m.counts(path) {
+			paths = append(paths, path)
+		}
+
+		return nil
+	})
+
+	return paths, cluerr.Wrap(err, "walking directory: "+dir).OrNil()
+}
+
+// openURL streams the body of the response from url.
+func openURL(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, cluerr.Wrap(err, "building request")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, cluerr.Wrap(err, "requesting url")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+
+		return nil, cluerr.New("unexpected response status").
+			With("status", resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
+// isURL is true if the argument is an http or https url.
+func isURL(arg string) bool {
+	return strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://")
+}
+
+// objectSchemes maps the schemes of object storage urls to the public
+// https endpoint of their objects.  Objects are read anonymously, so
+// only publicly readable objects can be counted.
+var objectSchemes = map[string]func(bucket, key string) string{
+	"s3://": func(bucket, key string) string {
+		return "https://" + bucket + ".s3.amazonaws.com/" + key
+	},
+	"gs://": func(bucket, key string) string {
+		return "https://storage.googleapis.com/" + bucket + "/" + key
+	},
+}
+
+// isObject is true if the argument is an s3 or gcs object url.
+func isObject(arg string) bool {
+	for scheme := range objectSchemes {
+		if strings.HasPrefix(arg, scheme) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// objectURL converts an object storage url (ex: s3://bucket/key) into
+// the https url of the object.
+func objectURL(arg string) string {
+	for scheme, endpoint := range objectSchemes {
+		rest, ok := strings.CutPrefix(arg, scheme)
+		if !ok {
+			continue
+		}
+
+		bucket, key, _ := strings.Cut(rest, "/")
+		segments := strings.Split(key, "/")
+
+		for i, seg := range segments {
+			segments[i] = url.PathEscape(seg)
+		}
+
+		return endpoint(bucket, strings.Join(segments, "/"))
+	}
+
+	return arg
+}
+
+// isLocal is true if the argument refers to the local filesystem,
+// rather than to stdin, a url, or an object in object storage.
+func isLocal(arg string) bool {
+	return arg != stdinPath && !isURL(arg) && !isObject(arg)
+}
+
+// readManifest reads one input per line from the file at path.
+// Blank lines, and comment lines starting with #, are skipped.
+func readManifest(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, cluerr.Wrap(err, "opening manifest")
+	}
+
+	defer f.Close()
+
+	var (
+		inputs  = []string{}
+		scanner = bufio.NewScanner(f)
+	)
+
+	for scanner.Scan() {
+		ln := strings.TrimSpace(scanner.Text())
+		if len(ln) == 0 || strings.HasPrefix(ln, "#") {
+			continue
+		}
+
+		inputs = append(inputs, ln)
+	}
+
+	return inputs, cluerr.Wrap(scanner.Err(), "reading manifest").OrNil()
+}
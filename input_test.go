@@ -0,0 +1,508 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// setStdin replaces stdin with the text until the test ends.
+func setStdin(t *testing.T, text string) {
+	t.Helper()
+
+	f, err := os.Open(writeInput(t, t.TempDir(), "stdin.txt", text))
+	if err != nil {
+		t.Fatalf("opening stdin: %v", err)
+	}
+
+	stdin := os.Stdin
+	os.Stdin = f
+
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		f.Close()
+	})
+}
+
+func TestStdinInput(t *testing.T) {
+	table := []struct {
+		name string
+		args []string
+	}{
+		{"dash", []string{stdinPath}},
+		{"no arguments", nil},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			setStdin(t, "piped in words\npiped\n")
+
+			h, _ := runCount(t, test.args...)
+
+			if got := countOf(h.words.universal, "piped"); got != 2 {
+				t.Errorf("count of piped = %d, want 2", got)
+			}
+
+			if got := h.words.count.Value(); got != 4 {
+				t.Errorf("word count = %d, want 4", got)
+			}
+		})
+	}
+}
+
+func TestStdinAlongsideFiles(t *testing.T) {
+	setStdin(t, "piped\n")
+
+	input := writeInput(t, t.TempDir(), "in.txt", "written\n")
+
+	h, _ := runCount(t, input, stdinPath)
+
+	for _, word := range []string{"piped", "written"} {
+		if got := countOf(h.words.universal, word); got != 1 {
+			t.Errorf("count of %s = %d, want 1", word, got)
+		}
+	}
+}
+
+// writeTree writes a file holding the word text at each path within
+// dir, creating any directories along the way.  Paths ending in a
+// slash are created as empty directories.  Produces dir.
+func writeTree(t *testing.T, dir string, paths ...string) string {
+	t.Helper()
+
+	for _, p := range paths {
+		isDir := strings.HasSuffix(p, "/")
+		p = filepath.Join(dir, filepath.FromSlash(p))
+
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("creating directory: %v", err)
+		}
+
+		if isDir {
+			if err := os.Mkdir(p, 0o755); err != nil {
+				t.Fatalf("creating directory: %v", err)
+			}
+
+			continue
+		}
+
+		if err := os.WriteFile(p, []byte("text\n"), 0o644); err != nil {
+			t.Fatalf("writing %s: %v", p, err)
+		}
+	}
+
+	return dir
+}
+
+// relPaths produces each of the paths relative to dir, with forward
+// slashes.
+func relPaths(t *testing.T, dir string, paths []string) []string {
+	t.Helper()
+
+	rel := make([]string, 0, len(paths))
+
+	for _, p := range paths {
+		r, err := filepath.Rel(dir, p)
+		if err != nil {
+			t.Fatalf("relative path: %v", err)
+		}
+
+		rel = append(rel, filepath.ToSlash(r))
+	}
+
+	return rel
+}
+
+func TestResolveDirectory(t *testing.T) {
+	dir := writeTree(
+		t,
+		t.TempDir(),
+		"b.txt",
+		"a.txt",
+		"notes.md",
+		"sub/c.txt",
+		"sub/deeper/d.txt",
+		"sub/deeper/image.png",
+		"empty/",
+	)
+
+	m := inputMatcher{exts: []string{".txt"}}
+
+	paths, err := m.resolveInputs([]string{dir})
+	if err != nil {
+		t.Fatalf("resolving inputs: %v", err)
+	}
+
+	want := []string{"a.txt", "b.txt", "sub/c.txt", "sub/deeper/d.txt"}
+	if got := relPaths(t, dir, paths); !slices.Equal(got, want) {
+		t.Errorf("paths = %q, want %q", got, want)
+	}
+}
+
+func TestDirectoryInput(t *testing.T) {
+	dir := writeTree(t, t.TempDir(), "top.txt", "sub/nested.txt", "sub/skipped.md")
+
+	h, _ := runCount(t, dir)
+
+	if got := countOf(h.words.universal, "text"); got != 2 {
+		t.Errorf("count of text = %d, want 2", got)
+	}
+}
+
+func TestResolveGlobs(t *testing.T) {
+	dir := writeTree(
+		t,
+		t.TempDir(),
+		"b.txt",
+		"a.txt",
+		"notes.md",
+		"sub/c.txt",
+		"sub/deeper/d.txt",
+		"sub/deeper/e.log",
+	)
+
+	table := []struct {
+		name, glob string
+		want       []string
+	}{
+		{"shallow", "*.txt", []string{"a.txt", "b.txt"}},
+		{"deep", "**/*.txt", []string{"a.txt", "b.txt", "sub/c.txt", "sub/deeper/d.txt"}},
+		{"single character", "?.txt", []string{"a.txt", "b.txt"}},
+		{"class", "[a].txt", []string{"a.txt"}},
+		{"alternatives", "sub/**/{c,d}.txt", []string{"sub/c.txt", "sub/deeper/d.txt"}},
+		// matches without a counted extension are skipped.
+		{"uncounted matches", "sub/deeper/*", []string{"sub/deeper/d.txt"}},
+	}
+
+	m := inputMatcher{exts: []string{".txt"}}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			paths, err := m.resolveInputs([]string{filepath.Join(dir, test.glob)})
+			if err != nil {
+				t.Fatalf("resolving inputs: %v", err)
+			}
+
+			if got := relPaths(t, dir, paths); !slices.Equal(got, test.want) {
+				t.Errorf("paths = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestResolveGlobErrors(t *testing.T) {
+	dir := writeTree(t, t.TempDir(), "notes.md")
+
+	m := inputMatcher{exts: []string{".txt"}}
+
+	for _, glob := range []string{"*.txt", "*.md"} {
+		_, err := m.resolveInputs([]string{filepath.Join(dir, glob)})
+		if err == nil || !strings.Contains(err.Error(), "no countable files match glob") {
+			t.Errorf("resolving %s: error = %v, want a no matches error", glob, err)
+		}
+	}
+}
+
+func TestGlobInput(t *testing.T) {
+	dir := writeTree(t, t.TempDir(), "a.txt", "sub/b.txt", "sub/c.md")
+
+	h, _ := runCount(t, filepath.Join(dir, "**", "*.txt"))
+
+	if got := countOf(h.words.universal, "text"); got != 2 {
+		t.Errorf("count of text = %d, want 2", got)
+	}
+}
+
+// serveCorpus serves the text at every path, other than /missing,
+// which responds not found.
+func serveCorpus(t *testing.T, text string) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+
+		io.WriteString(w, text)
+	}))
+
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestOpenURL(t *testing.T) {
+	srv := serveCorpus(t, "served words\n")
+
+	rc, err := openURL(context.Background(), srv.URL+"/book.txt")
+	if err != nil {
+		t.Fatalf("opening url: %v", err)
+	}
+
+	defer rc.Close()
+
+	body, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("reading body: %v", err)
+	}
+
+	if string(body) != "served words\n" {
+		t.Errorf("body = %q, want %q", body, "served words\n")
+	}
+
+	_, err = openURL(context.Background(), srv.URL+"/missing")
+	if err == nil || !strings.Contains(err.Error(), "unexpected response status") {
+		t.Errorf("error = %v, want an unexpected status error", err)
+	}
+}
+
+func TestURLInput(t *testing.T) {
+	srv := serveCorpus(t, "served words\nserved\n")
+
+	h, _ := runCount(t, srv.URL+"/book.txt")
+
+	if got := countOf(h.words.universal, "served"); got != 2 {
+		t.Errorf("count of served = %d, want 2", got)
+	}
+
+	err := execCount(newHandler(), srv.URL+"/missing")
+	if err == nil || !strings.Contains(err.Error(), "unexpected response status") {
+		t.Errorf("error = %v, want an unexpected status error", err)
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	manifest := writeInput(t, t.TempDir(), "inputs.txt", `# corpora to count
+first.txt
+
+  second.txt  
+	# an indented comment
+https://example.com/third.txt
+`)
+
+	inputs, err := readManifest(manifest)
+	if err != nil {
+		t.Fatalf("reading manifest: %v", err)
+	}
+
+	want := []string{"first.txt", "second.txt", "https://example.com/third.txt"}
+	if !slices.Equal(inputs, want) {
+		t.Errorf("inputs = %q, want %q", inputs, want)
+	}
+
+	_, err = readManifest(filepath.Join(t.TempDir(), "missing.txt"))
+	if err == nil || !strings.Contains(err.Error(), "opening manifest") {
+		t.Errorf("error = %v, want an opening manifest error", err)
+	}
+}
+
+func TestManifestInput(t *testing.T) {
+	var (
+		dir      = t.TempDir()
+		arg      = writeInput(t, dir, "arg.txt", "argued\n")
+		listed   = writeInput(t, dir, "listed.txt", "listed\n")
+		srv      = serveCorpus(t, "served\n")
+		manifest = writeInput(t, dir, "inputs.txt", "# listed inputs\n"+listed+"\n\n"+srv.URL+"/book.txt\n")
+	)
+
+	h, _ := runCount(t, "--fromFile="+manifest, arg)
+
+	for _, word := range []string{"argued", "listed", "served"} {
+		if got := countOf(h.words.universal, word); got != 1 {
+			t.Errorf("count of %s = %d, want 1", word, got)
+		}
+	}
+}
+
+func TestInputMatcherCounts(t *testing.T) {
+	table := []struct {
+		name string
+		m    inputMatcher
+		path string
+		want bool
+	}{
+		{"counted extension", inputMatcher{exts: []string{".txt"}}, "book.txt", true},
+		{"uncounted extension", inputMatcher{exts: []string{".txt"}}, "book.md", false},
+		{"compressed", inputMatcher{exts: []string{".txt"}}, "book.txt.gz", true},
+		{"second extension", inputMatcher{exts: []string{".txt", ".md"}}, "notes.md", true},
+		{"no extension", inputMatcher{exts: []string{".txt"}}, "README", false},
+		{"any file", inputMatcher{anyFile: true}, "README", true},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.m.counts(test.path); got != test.want {
+				t.Errorf("counts(%q) = %v, want %v", test.path, got, test.want)
+			}
+		})
+	}
+}
+
+func TestExtInputs(t *testing.T) {
+	dir := writeTree(t, t.TempDir(), "a.txt", "b.md", "c.log", "README")
+
+	table := []struct {
+		name string
+		args []string
+		want int64
+	}{
+		{"default", nil, 1},
+		{"one ext", []string{"--ext=md"}, 1},
+		{"comma separated", []string{"--ext=.md,.log"}, 2},
+		{"repeated", []string{"--ext=.md", "--ext=txt"}, 2},
+		{"any file", []string{"--any-file"}, 4},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			h, _ := runCount(t, append(test.args, dir)...)
+
+			if got := countOf(h.words.universal, "text"); got != test.want {
+				t.Errorf("count of text = %d, want %d", got, test.want)
+			}
+		})
+	}
+
+	err := execCount(newHandler(), "--ext=.md,", dir)
+	if err == nil || !strings.Contains(err.Error(), "ext cannot be empty") {
+		t.Errorf("error = %v, want an empty ext error", err)
+	}
+
+	err = execCount(newHandler(), filepath.Join(dir, "b.md"))
+	if err == nil || !strings.Contains(err.Error(), "must have a counted extension") {
+		t.Errorf("error = %v, want an uncounted extension error", err)
+	}
+}
+
+func TestResolveExcluded(t *testing.T) {
+	dir := writeTree(
+		t,
+		t.TempDir(),
+		"a.txt",
+		"a_index.txt",
+		"LICENSE.txt",
+		"drafts/b.txt",
+		"sub/c.txt",
+		"sub/c_index.txt",
+	)
+
+	table := []struct {
+		name    string
+		exclude []string
+		want    []string
+	}{
+		{
+			name: "none",
+			want: []string{"LICENSE.txt", "a.txt", "a_index.txt", "drafts/b.txt", "sub/c.txt", "sub/c_index.txt"},
+		},
+		{
+			name:    "file names",
+			exclude: []string{"*_index.txt", "LICENSE.txt"},
+			want:    []string{"a.txt", "drafts/b.txt", "sub/c.txt"},
+		},
+		{
+			name:    "directory",
+			exclude: []string{"drafts"},
+			want:    []string{"LICENSE.txt", "a.txt", "a_index.txt", "sub/c.txt", "sub/c_index.txt"},
+		},
+		{
+			name:    "deep path",
+			exclude: []string{"**/sub/*_index.txt"},
+			want:    []string{"LICENSE.txt", "a.txt", "a_index.txt", "drafts/b.txt", "sub/c.txt"},
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			m := inputMatcher{exts: []string{".txt"}, exclude: test.exclude}
+
+			paths, err := m.resolveInputs([]string{dir})
+			if err != nil {
+				t.Fatalf("resolving inputs: %v", err)
+			}
+
+			if got := relPaths(t, dir, paths); !slices.Equal(got, test.want) {
+				t.Errorf("paths = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestExcludeInputs(t *testing.T) {
+	dir := writeTree(t, t.TempDir(), "a.txt", "a_index.txt", "sub/b_index.txt")
+
+	h, _ := runCount(t, "--exclude=*_index.txt", filepath.Join(dir, "**", "*.txt"))
+
+	if got := countOf(h.words.universal, "text"); got != 1 {
+		t.Errorf("count of text = %d, want 1", got)
+	}
+
+	err := execCount(newHandler(), "--exclude=[", dir)
+	if err == nil || !strings.Contains(err.Error(), "invalid exclude glob") {
+		t.Errorf("error = %v, want an invalid glob error", err)
+	}
+}
+
+func TestObjectURL(t *testing.T) {
+	table := []struct {
+		arg, want string
+	}{
+		{"s3://corpora/books/alice.txt", "https://corpora.s3.amazonaws.com/books/alice.txt"},
+		{"gs://corpora/books/alice.txt", "https://storage.googleapis.com/corpora/books/alice.txt"},
+		{"s3://corpora/with space/a#b.txt", "https://corpora.s3.amazonaws.com/with%20space/a%23b.txt"},
+		{"https://example.com/a.txt", "https://example.com/a.txt"},
+	}
+
+	for _, test := range table {
+		if got := objectURL(test.arg); got != test.want {
+			t.Errorf("objectURL(%q) = %q, want %q", test.arg, got, test.want)
+		}
+	}
+
+	for arg, want := range map[string]bool{
+		"s3://bucket/key":  true,
+		"gs://bucket/key":  true,
+		"az://bucket/key":  false,
+		"bucket/key.txt":   false,
+		"https://host/key": false,
+	} {
+		if got := isObject(arg); got != want {
+			t.Errorf("isObject(%q) = %v, want %v", arg, got, want)
+		}
+	}
+}
+
+func TestObjectInput(t *testing.T) {
+	var requested string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		io.WriteString(w, "stored words\n")
+	}))
+	defer srv.Close()
+
+	// points s3 at the test server, rather than at aws.
+	s3 := objectSchemes["s3://"]
+	defer func() { objectSchemes["s3://"] = s3 }()
+
+	objectSchemes["s3://"] = func(bucket, key string) string {
+		return srv.URL + "/" + bucket + "/" + key
+	}
+
+	h, _ := runCount(t, "s3://corpora/books/alice.txt")
+
+	if requested != "/corpora/books/alice.txt" {
+		t.Errorf("requested %q, want %q", requested, "/corpora/books/alice.txt")
+	}
+
+	if got := countOf(h.words.universal, "stored"); got != 1 {
+		t.Errorf("count of stored = %d, want 1", got)
+	}
+}
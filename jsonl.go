@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/alcionai/clues/cluerr"
+)
+
+// jsonlExts holds the extensions of newline-delimited json files.
+var jsonlExts = []string{".jsonl", ".ndjson"}
+
+// isJSONL is true if the path is a newline-delimited json file.  They
+// can also be compressed (ex: .jsonl.gz).
+func isJSONL(p string) bool {
+	p = strings.ToLower(trimCompressedExt(p))
+
+	for _, ext := range jsonlExts {
+		if strings.HasSuffix(p, ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseFieldPath splits a dot-path (ex: data.posts.0.text) into its
+// keys.  Numeric keys also index into arrays.
+func parseFieldPath(s string) ([]string, error) {
+	s = strings.TrimSpace(s)
+
+	if len(s) == 0 {
+		return nil, cluerr.New("jsonl-field cannot be empty")
+	}
+
+	keys := strings.Split(s, ".")
+
+	for _, k := range keys {
+		if len(k) == 0 {
+			return nil, cluerr.New("jsonl-field cannot have empty keys").
+				With("input", s)
+		}
+	}
+
+	return keys, nil
+}
+
+// jsonlText produces the value of the field in each record, one per
+// line.  Records without the field, or where it isn't a string, are
+// skipped.  Closing the result stops the parsing.
+func jsonlText(r io.Reader, field []string) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		pw.CloseWithError(writeJSONLText(r, field, pw))
+	}()
+
+	return pr
+}
+
+func writeJSONLText(r io.Reader, field []string, w io.Writer) error {
+	dec := json.NewDecoder(r)
+
+	for record := 1; ; record++ {
+		var v any
+
+		err := dec.Decode(&v)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return cluerr.Wrap(err, "parsing jsonl record").
+				With("record", record)
+		}
+
+		text, ok := lookupField(v, field)
+		if !ok {
+			continue
+		}
+
+		if _, err := io.WriteString(w, text+"\n"); err != nil {
+			return err
+		}
+	}
+}
+
+// lookupField follows the keys through nested objects and arrays,
+// producing the string at the end of the path.
+func lookupField(v any, keys []string) (string, bool) {
+	for _, k := range keys {
+		switch t := v.(type) {
+		case map[string]any:
+			v = t[k]
+		case []any:
+			i, err := strconv.Atoi(k)
+			if err != nil || i < 0 || i >= len(t) {
+				return "", false
+			}
+
+			v = t[i]
+		default:
+			return "", false
+		}
+	}
+
+	s, ok := v.(string)
+
+	return s, ok
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+const testJSONL = `{"data": {"text": "nested text", "tags": ["first tag", "second tag"]}}
+{"data": {"title": "missing text"}}
+{"data": {"text": 42}}
+{"data": "not an object"}
+
+{"data": {"text": "after a blank line"}}
+`
+
+func TestJSONLText(t *testing.T) {
+	table := []struct {
+		name, field, want string
+	}{
+		{"nested key", "data.text", "nested text\nafter a blank line\n"},
+		{"array index", "data.tags.1", "second tag\n"},
+		{"index out of range", "data.tags.2", ""},
+		{"missing field", "data.body", ""},
+		{"non-string field", "data", "not an object\n"},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			field, err := parseFieldPath(test.field)
+			if err != nil {
+				t.Fatalf("parsing field: %v", err)
+			}
+
+			text := jsonlText(strings.NewReader(testJSONL), field)
+			defer text.Close()
+
+			got, err := io.ReadAll(text)
+			if err != nil {
+				t.Fatalf("reading field: %v", err)
+			}
+
+			if string(got) != test.want {
+				t.Errorf("field text = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestJSONLTextMalformed(t *testing.T) {
+	text := jsonlText(strings.NewReader("{\"text\": \"fine\"}\n{\"text\": oops}\n"), []string{"text"})
+	defer text.Close()
+
+	got, err := io.ReadAll(text)
+	if err == nil || !strings.Contains(err.Error(), "parsing jsonl record") {
+		t.Errorf("error = %v, want a jsonl parsing error", err)
+	}
+
+	// records ahead of the malformed one are still produced.
+	if string(got) != "fine\n" {
+		t.Errorf("field text = %q, want %q", got, "fine\n")
+	}
+}
+
+func TestParseFieldPathErrors(t *testing.T) {
+	for _, s := range []string{"", " ", "data..text", ".text", "data."} {
+		if _, err := parseFieldPath(s); err == nil {
+			t.Errorf("parseFieldPath(%q) should error", s)
+		}
+	}
+}
+
+func TestJSONLFieldInput(t *testing.T) {
+	dir := t.TempDir()
+	input := writeInput(t, dir, "posts.jsonl", testJSONL)
+
+	h, _ := runCount(t, "--jsonl-field=data.text", input)
+
+	for word, want := range map[string]int64{
+		"nested":  1,
+		"blank":   1,
+		"missing": 0,
+		"tag":     0,
+		"data":    0,
+	} {
+		if got := countOf(h.words.universal, word); got != want {
+			t.Errorf("count of %s = %d, want %d", word, got, want)
+		}
+	}
+
+	malformed := writeInput(t, dir, "bad.jsonl", "{\"data\": \n")
+
+	err := execCount(newHandler(), "--jsonl-field=data.text", malformed)
+	if err == nil || !strings.Contains(err.Error(), "parsing jsonl record") {
+		t.Errorf("error = %v, want a jsonl parsing error", err)
+	}
+}
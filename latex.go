@@ -0,0 +1,86 @@
+package main
+
+import (
+	"regexp"
+)
+
+// latexMathEnvs holds the environments whose contents are math,
+// and never prose.
+const latexMathEnvs = `(equation|align|alignat|flalign|gather|multline|eqnarray|math|displaymath)\*?`
+
+// latexVerbatimEnvs holds the environments whose contents are code or
+// other literal text, and never prose.
+const latexVerbatimEnvs = `(verbatim|lstlisting|minted|comment)\*?`
+
+var (
+	// an unescaped % comments out the rest of the line.
+	latexCommentRE    = regexp.MustCompile(`(^|[^\\])%.*`)
+	latexMathBeginRE  = regexp.MustCompile(`\\begin\{` + latexMathEnvs + `\}`)
+	latexMathEndRE    = regexp.MustCompile(`\\end\{` + latexMathEnvs + `\}`)
+	latexVerbBeginRE  = regexp.MustCompile(`\\begin\{` + latexVerbatimEnvs + `\}`)
+	latexVerbEndRE    = regexp.MustCompile(`\\end\{` + latexVerbatimEnvs + `\}`)
+	latexDisplayRE    = regexp.MustCompile(`^\s*(\$\$|\\\[|\\\])\s*$`)
+	latexInlineMathRE = regexp.MustCompile(`\$\$.*?\$\$|\$[^$]*\$|\\\(.*?\\\)|\\\[.*?\\\]`)
+	// commands whose arguments are references, keys, or paths rather
+	// than prose, and get dropped along with the command.
+	latexDroppedRE = regexp.MustCompile(`\\(label|ref|eqref|pageref|autoref|cref|cite[a-z]*|includegraphics|usepackage|documentclass|begin|end|bibliography|bibliographystyle|input|include|url|href|newcommand|renewcommand|vspace|hspace)\*?(\[[^\]]*\])*(\{[^}]*\})?`)
+	// any other command is dropped, keeping its arguments as prose
+	// (ex: \section{intro} counts intro).
+	latexCommandRE = regexp.MustCompile(`\\([a-zA-Z]+\*?|.)(\[[^\]]*\])?`)
+)
+
+// stripLatex removes comments, inline math, and commands from the
+// line, so that only its prose remains.
+func stripLatex(ln string) string {
+	ln = latexCommentRE.ReplaceAllString(ln, "$1")
+	ln = latexInlineMathRE.ReplaceAllString(ln, " ")
+	ln = latexDroppedRE.ReplaceAllString(ln, " ")
+
+	return latexCommandRE.ReplaceAllString(ln, " ")
+}
+
+// latexState tracks whether a line falls within a math environment,
+// display math block, or verbatim environment, which can span many
+// lines.
+type latexState struct {
+	inMath     bool
+	inVerbatim bool
+}
+
+// next advances the state past the line, and reports whether the line
+// falls outside of math and verbatim text.  The lines that open and
+// close them are part of them.
+func (ls *latexState) next(ln string) bool {
+	// verbatim text has no comments, so only its end can close it.
+	if ls.inVerbatim {
+		ls.inVerbatim = !latexVerbEndRE.MatchString(ln)
+		return false
+	}
+
+	ln = latexCommentRE.ReplaceAllString(ln, "$1")
+
+	switch {
+	case latexVerbBeginRE.MatchString(ln) && !ls.inMath:
+		// verbatim that opens and closes on the same line.
+		ls.inVerbatim = !latexVerbEndRE.MatchString(ln)
+		return false
+
+	case ls.inMath:
+		if latexMathEndRE.MatchString(ln) || latexDisplayRE.MatchString(ln) {
+			ls.inMath = false
+		}
+
+		return false
+
+	case latexMathBeginRE.MatchString(ln):
+		// math that opens and closes on the same line.
+		ls.inMath = !latexMathEndRE.MatchString(ln)
+		return false
+
+	case latexDisplayRE.MatchString(ln):
+		ls.inMath = true
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// braces get stripped along with the rest of the punctuation during
+// normalization.
+var braceReplacer = strings.NewReplacer("{", " ", "}", " ")
+
+func TestStripLatex(t *testing.T) {
+	table := []struct {
+		name string
+		ln   string
+		want []string
+	}{
+		{"command argument", `\section{intro} text`, []string{"intro", "text"}},
+		{"optional argument", `\section[short]{long title}`, []string{"long", "title"}},
+		{"nested commands", `\emph{\textbf{bold}} words`, []string{"bold", "words"}},
+		{"dropped arguments", `see \ref{fig:one} and \cite[p. 2]{knuth}`, []string{"see", "and"}},
+		{"comment", `prose % a comment`, []string{"prose"}},
+		{"whole line comment", `% a comment`, nil},
+		{"escaped percent", `50\% off % but not this`, []string{"50", "off"}},
+		{"inline math", `where $x = y$ holds`, []string{"where", "holds"}},
+		{"paren math", `where \(x = y\) holds`, []string{"where", "holds"}},
+		{"display math", `where $$x = y$$ holds`, []string{"where", "holds"}},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			got := strings.Fields(braceReplacer.Replace(stripLatex(test.ln)))
+			if !slices.Equal(got, test.want) {
+				t.Errorf("stripLatex(%q) = %q, want %q", test.ln, got, test.want)
+			}
+		})
+	}
+}
+
+func TestLatexState(t *testing.T) {
+	table := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{
+			name: "math environment",
+			lines: []string{
+				`before`,
+				`\begin{equation}`,
+				`x = y`,
+				`\end{equation}`,
+				`after`,
+			},
+			want: []string{"before", "after"},
+		},
+		{
+			name: "starred math environment",
+			lines: []string{
+				`\begin{align*}`,
+				`x &= y`,
+				`\end{align*}`,
+				`after`,
+			},
+			want: []string{"after"},
+		},
+		{
+			name:  "single line math environment",
+			lines: []string{`\begin{math} x \end{math}`, `after`},
+			want:  []string{"after"},
+		},
+		{
+			name:  "display math",
+			lines: []string{`\[`, `x = y`, `\]`, `$$`, `a = b`, `$$`, `after`},
+			want:  []string{"after"},
+		},
+		{
+			name:  "commented begin",
+			lines: []string{`% \begin{equation}`, `prose`},
+			want:  []string{`% \begin{equation}`, "prose"},
+		},
+		{
+			name: "verbatim",
+			lines: []string{
+				`\begin{verbatim}`,
+				`$$`,
+				`% not a comment \end{equation}`,
+				`\end{verbatim}`,
+				`after`,
+			},
+			want: []string{"after"},
+		},
+		{
+			name: "listing",
+			lines: []string{
+				`\begin{lstlisting}[language=go]`,
+				`func main() {}`,
+				`\end{lstlisting}`,
+				`after`,
+			},
+			want: []string{"after"},
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				ls    latexState
+				prose = []string{}
+			)
+
+			for _, ln := range test.lines {
+				if ls.next(ln) {
+					prose = append(prose, ln)
+				}
+			}
+
+			if !slices.Equal(prose, test.want) {
+				t.Errorf("prose = %q, want %q", prose, test.want)
+			}
+		})
+	}
+}
+
+func TestLatexInput(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "paper.tex", `\section{results}
+the results hold % unless they don't
+\begin{equation}
+e = mc^2
+\end{equation}
+\begin{verbatim}
+printed code
+\end{verbatim}
+`)
+
+	h, _ := runCount(t, "--latex", "--ext=tex", input)
+
+	for word, want := range map[string]int64{
+		"results": 2,
+		"hold":    1,
+		"unless":  0,
+		"mc":      0,
+		"printed": 0,
+		"section": 0,
+	} {
+		if got := countOf(h.words.universal, word); got != want {
+			t.Errorf("count of %s = %d, want %d", word, got, want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/alcionai/clues/cluerr"
+)
+
+// lineRange selects the lines of each file that get counted, using
+// 1-based line numbers.  Both bounds are inclusive, and 0 leaves the
+// bound open.
+type lineRange struct {
+	start int
+	end   int
+}
+
+// parseLineRange parses a START:END range (ex: 10:200).  Either bound
+// can be omitted (ex: 10: or :200).
+func parseLineRange(s string) (lineRange, error) {
+	var lr lineRange
+
+	if len(s) == 0 {
+		return lr, nil
+	}
+
+	start, end, ok := strings.Cut(s, ":")
+	if !ok {
+		return lr, cluerr.New("lines must be formatted as START:END").
+			With("input", s)
+	}
+
+	for _, bound := range []struct {
+		s string
+		v *int
+	}{
+		{start, &lr.start},
+		{end, &lr.end},
+	} {
+		if len(bound.s) == 0 {
+			continue
+		}
+
+		n, err := strconv.Atoi(bound.s)
+		if err != nil {
+			return lr, cluerr.Wrap(err, "parsing lines").
+				With("input", s)
+		}
+
+		if n < 1 {
+			return lr, cluerr.New("line numbers start at 1").
+				With("input", s)
+		}
+
+		*bound.v = n
+	}
+
+	if lr.end > 0 && lr.end < lr.start {
+		return lr, cluerr.New("lines cannot end before they start").
+			With("input", s)
+	}
+
+	return lr, nil
+}
+
+// skip narrows the range to exclude the first n lines.
+func (lr lineRange) skip(n int) lineRange {
+	lr.start = max(lr.start, n+1)
+	return lr
+}
+
+// contains is true if the line number falls within the range.
+func (lr lineRange) contains(n int) bool {
+	return n >= lr.start && (lr.end == 0 || n <= lr.end)
+}
+
+// past is true if the line number falls after the end of the range.
+func (lr lineRange) past(n int) bool {
+	return lr.end > 0 && n > lr.end
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseLineRange(t *testing.T) {
+	table := []struct {
+		name    string
+		input   string
+		want    lineRange
+		wantErr string
+	}{
+		{"unset", "", lineRange{}, ""},
+		{"both bounds", "10:200", lineRange{start: 10, end: 200}, ""},
+		{"open end", "10:", lineRange{start: 10}, ""},
+		{"open start", ":200", lineRange{end: 200}, ""},
+		{"single line", "7:7", lineRange{start: 7, end: 7}, ""},
+		{"no separator", "10", lineRange{}, "formatted as START:END"},
+		{"not a number", "a:10", lineRange{}, "parsing lines"},
+		{"zero", "0:10", lineRange{}, "line numbers start at 1"},
+		{"backwards", "20:10", lineRange{}, "cannot end before they start"},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseLineRange(test.input)
+
+			if len(test.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("expected error containing %q, got %v", test.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parsing %q: %v", test.input, err)
+			}
+
+			if got != test.want {
+				t.Errorf("parseLineRange(%q) = %+v, want %+v", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+func TestLineRange(t *testing.T) {
+	lr := lineRange{start: 3, end: 5}
+
+	for n, want := range map[int]bool{1: false, 3: true, 5: true, 6: false} {
+		if got := lr.contains(n); got != want {
+			t.Errorf("contains(%d) = %v, want %v", n, got, want)
+		}
+	}
+
+	if lr.past(5) || !lr.past(6) {
+		t.Error("expected only lines after 5 to be past the range")
+	}
+
+	if (lineRange{}).past(1_000_000) {
+		t.Error("an open range should never be passed")
+	}
+
+	table := []struct {
+		name string
+		lr   lineRange
+		skip int
+		want lineRange
+	}{
+		{"open range", lineRange{}, 4, lineRange{start: 5}},
+		{"skip within start", lineRange{start: 10, end: 20}, 4, lineRange{start: 10, end: 20}},
+		{"skip past start", lineRange{start: 2, end: 20}, 4, lineRange{start: 5, end: 20}},
+		{"no skip", lineRange{start: 2}, 0, lineRange{start: 2}},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.lr.skip(test.skip); got != test.want {
+				t.Errorf("skip(%d) = %+v, want %+v", test.skip, got, test.want)
+			}
+		})
+	}
+}
+
+func TestLinesInput(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "one\ntwo\nthree\nfour\nfive\nsix\n")
+
+	table := []struct {
+		name  string
+		flags []string
+		want  []string
+	}{
+		{"range", []string{"--lines=2:4"}, []string{"two", "three", "four"}},
+		{"open end", []string{"--lines=5:"}, []string{"five", "six"}},
+		{"skip", []string{"--skip-lines=4"}, []string{"five", "six"}},
+		{"skip within range", []string{"--lines=2:4", "--skip-lines=2"}, []string{"three", "four"}},
+	}
+
+	all := []string{"one", "two", "three", "four", "five", "six"}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			h, _ := runCount(t, append(test.flags, input)...)
+
+			for _, word := range all {
+				want := int64(0)
+				if slices.Contains(test.want, word) {
+					want = 1
+				}
+
+				if got := countOf(h.words.universal, word); got != want {
+					t.Errorf("count of %q = %d, want %d", word, got, want)
+				}
+			}
+		})
+	}
+
+	err := execCount(newHandler(), "--skip-lines=-1", input)
+	if err == nil || !strings.Contains(err.Error(), "skip-lines cannot be negative") {
+		t.Errorf("expected a negative skip-lines error, got %v", err)
+	}
+
+	err = execCount(newHandler(), "--lines=4:2", input)
+	if err == nil || !strings.Contains(err.Error(), "cannot end before they start") {
+		t.Errorf("expected a backwards range error, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/alcionai/clues/clog"
+	"github.com/alcionai/clues/cluerr"
+)
+
+type logSink string
+
+const (
+	sinkStderr logSink = "stderr"
+	sinkSyslog logSink = "syslog"
+	sinkFile   logSink = "file"
+)
+
+var logSinks = []logSink{
+	sinkStderr,
+	sinkSyslog,
+	sinkFile,
+}
+
+func logSinkNames() []string {
+	names := make([]string, 0, len(logSinks))
+
+	for _, s := range logSinks {
+		names = append(names, string(s))
+	}
+
+	return names
+}
+
+// syslogPath is the log output path that routes logs to the
+// syslog sink.  See syslog_unix.go.
+const syslogPath = "syslog:"
+
+// logSettings produces the clog settings for the configured log sink.
+// Results are always written to stdout (or the output file), so routing
+// logs elsewhere keeps diagnostics separate from results.
+func logSettings(sink, file string) (clog.Settings, error) {
+	set := clog.Settings{}
+
+	if !slices.Contains(logSinks, logSink(sink)) {
+		return set, cluerr.New("unsupported log-sink, expected one of: "+strings.Join(logSinkNames(), ", ")).
+			With("input", sink)
+	}
+
+	if logSink(sink) != sinkFile && len(file) > 0 {
+		return set, cluerr.New("log-file requires --log-sink=file")
+	}
+
+	switch logSink(sink) {
+	case sinkSyslog:
+		if !syslogSupported {
+			return set, cluerr.New("syslog is not supported on this platform")
+		}
+
+		set, err := set.LogToFile(syslogPath)
+
+		return set, cluerr.Wrap(err, "routing logs to syslog").OrNil()
+
+	case sinkFile:
+		set, err := set.LogToFile(file)
+
+		return set, cluerr.Wrap(err, "routing logs to file").OrNil()
+	}
+
+	return set, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alcionai/clues/clog"
+)
+
+func TestLogSettings(t *testing.T) {
+	table := []struct {
+		name    string
+		sink    string
+		file    string
+		wantErr bool
+	}{
+		{"stderr", "stderr", "", false},
+		{"file", "file", filepath.Join(t.TempDir(), "count.log"), false},
+		{"file without a path", "file", "", true},
+		{"path without the file sink", "stderr", "count.log", true},
+		{"unsupported", "kafka", "", true},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := logSettings(test.sink, test.file)
+			if (err != nil) != test.wantErr {
+				t.Errorf("error = %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+// logFileEnv, when set, makes TestLogSinkFile count a binary input
+// with logs routed to the file it names.
+const logFileEnv = "COUNT_TEST_LOG_FILE"
+
+func TestLogSinkFile(t *testing.T) {
+	// the logger is initialized once per process, so the logs get
+	// routed from a separate run of the test binary.
+	if logFile := os.Getenv(logFileEnv); len(logFile) > 0 {
+		input := writeInput(t, t.TempDir(), "binary.txt", "\x01\x02\x03\x00\x00\x04")
+
+		if err := execCount(newHandler(), "--log-sink=file", "--log-file="+logFile, input); err != nil {
+			t.Fatalf("counting: %v", err)
+		}
+
+		clog.Flush(context.Background())
+
+		return
+	}
+
+	logFile := filepath.Join(t.TempDir(), "logs", "count.log")
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestLogSinkFile$")
+	cmd.Env = append(os.Environ(), logFileEnv+"="+logFile)
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("running count: %v\n%s", err, out)
+	}
+
+	bs, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("reading log file: %v", err)
+	}
+
+	if !strings.Contains(string(bs), "skipping file with nul or control bytes") {
+		t.Errorf("log file is missing the skipped file:\n%s", bs)
+	}
+
+	if strings.Contains(string(out), "skipping file") {
+		t.Errorf("logs were written to stderr:\n%s", out)
+	}
+}
@@ -3,17 +3,26 @@ package main
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"math/rand/v2"
 	"os"
+	"path/filepath"
 	"regexp"
 	"slices"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/alcionai/clues/clog"
 	"github.com/alcionai/clues/cluerr"
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/pawelszydlo/humanize"
 	"github.com/puzpuzpuz/xsync/v4"
+	"github.com/rivo/uniseg"
 	"github.com/spf13/cobra"
 )
 
@@ -21,6 +30,94 @@ var (
 	flagValSwap       []string
 	flagValRemove     []string
 	flagValRemoveHTML bool
+	flagValStripped   bool
+	flagValSample     int
+	flagValSeed       int64
+	flagValStats      bool
+	flagValOnlySwap   bool
+	flagValRuntime    time.Duration
+	flagValRegion     string
+	flagValFormat     string
+	flagValOutput     string
+	flagValFlushEvery int
+	flagValScrabble   bool
+	flagValBaseline   string
+	flagValTolerance  float64
+	flagValAlnum      bool
+	flagValTopWords   []int
+	flagValVowels     bool
+	flagValSaveState  string
+	flagValLoadState  string
+	flagValPerLine    bool
+	flagValRankLabel  string
+	flagValStrict     bool
+	flagValStripRepl  string
+	flagValLineFilter string
+	flagValEmitNorm   string
+	flagValLocDigits  bool
+	flagValFoldDigits bool
+	flagValSwapRatio  bool
+	flagValBlocks     bool
+	flagValPerFile    bool
+	flagValInterleave bool
+	flagValDistinct   int
+	flagValMDLinks    bool
+	flagValLogSink    string
+	flagValLogFile    string
+	flagValGeminates  bool
+	flagValPercentile bool
+	flagValNormFiles  bool
+	flagValRmAfterSwp bool
+	flagValTrigrams   string
+	flagValDelimiter  string
+	flagValVocabAfter string
+	flagValDenyClass  []string
+	flagValRarity     int
+	flagValOnlyChange bool
+	flagValCommonWord bool
+	flagValReportFile string
+	flagValGrowth     bool
+	flagValSIPrecise  int
+	flagValOrder      string
+	flagValHideRegex  string
+	flagValOnsets     bool
+	flagValAutoApprox int
+	flagValFromFile   string
+	flagValExt        []string
+	flagValAnyFile    bool
+	flagValExclude    []string
+	flagValGutenberg  bool
+	flagValMarkdown   bool
+	flagValCSVColumn  string
+	flagValJSONLField string
+	flagValLatex      bool
+	flagValSkipQuoted bool
+	flagValWatch      bool
+	flagValSampleRate float64
+	flagValLines      string
+	flagValSkipLines  int
+	flagValASCIIOnly  bool
+	flagValUniNorm    string
+	flagValFoldMarks  bool
+	flagValApostrophe bool
+	flagValSplitContr bool
+	flagValHyphens    string
+	flagValNumbers    string
+	flagValKeepChars  string
+	flagValSplitIdent bool
+	flagValSegmentCJK string
+	flagValEmoji      bool
+	flagValTokenizer  string
+	flagValMinWordLen int
+	flagValMaxWordLen int
+	flagValStopwords  []string
+	flagValRemoveFile string
+	flagValRemoveRE   []string
+	flagValSwapFile   string
+	flagValSwapRegex  []string
+	flagValSwapWhole  bool
+	flagValCaseSens   bool
+	flagValSaveBase   string
 )
 
 func newRoot(h *handler) *cobra.Command {
@@ -32,26 +129,55 @@ as an aggregate of all provided corpuses.  In addition, it
 extends functionality with letter-set swapping (ex: th->ð),
 and word slicing (ex: ignore all "the").
 
-Accepts a list of filepaths to .txt files as arguments.
-
-Example: count -swapNgram=th,ð -removeWord=the ~/corpus/alice_in_wonderland.txt
+Accepts a list of filepaths to .txt files as arguments.  Other
+extensions can be counted with --ext, or all files with --any-file.
+A path of - reads from stdin, as does providing no paths at all.  A
+directory counts every .txt file within it, recursively.  Globs
+are expanded by count itself, with ** matching any count of
+directories (ex: 'corpus/**/*.txt').  http and https urls, and
+public s3:// and gs:// objects, are streamed directly, without
+saving them to disk.  Files ending in .txt.gz or .txt.zst are
+decompressed while they're counted.  The .txt members of .zip and
+.tar (.tar.gz, .tar.zst) archives are each counted as a separate
+corpus.  The text layer of local .pdf files,
+and the prose of local .epub and .docx files, are extracted and
+counted.  Only the dialogue of .srt and .vtt subtitles is counted,
+and only the plain text message bodies of .mbox mail archives.
+
+Swaps apply in the order they're given, each one rewriting the
+output of the last, so rules can build on each other (ex: -s=th,ð
+-s=ða,đa).  All -s swaps run first, then --swapFile swaps, then
+--swapRegex swaps.
+
+Swaps can also increase the count of letters in a word (ex:
+-s=e,ea).  The raw and swapped columns each track their own totals.
+
+Example: count -swapNgram=th,ð -removeWord=the ~/corpus/alice_in_wonderland.txt`,
+		Example:           recipesExample(),
+		Args:              cobra.ArbitraryArgs,
+		PersistentPreRunE: initLogging,
+		RunE:              h.run,
+	}
 
-Caveats:
+	root.AddCommand(newVersion())
+	root.AddCommand(newRankDiff())
+	root.AddCommand(newExamples())
 
-As a simplification, assumes swaps always maintain the same
-count of letters in a word, or reduces them.  Increasing the
-letter count (ex: -s=e,ea) will cause stats issues in the 
-forth letters column.
+	pflags := root.PersistentFlags()
 
-Currently strips all non-ascii characters during the alpha-
-numeric corpus normalization.
+	pflags.StringVar(
+		&flagValLogSink,
+		"log-sink",
+		string(sinkStderr),
+		"where logs get written, one of: "+strings.Join(logSinkNames(), ", ")+". ex --log-sink=syslog",
+	)
 
-The RemoveHTML flag is a low-effort attempt and assumes all
-words beginning or ending in angle brackets (<>) can be removed.
-This is, of course, faulty.  But sufficient for simple use cases.`,
-		Args: cobra.MinimumNArgs(1),
-		RunE: h.run,
-	}
+	pflags.StringVar(
+		&flagValLogFile,
+		"log-file",
+		"",
+		"the file logs get written to, when --log-sink=file. ex --log-file=count.log",
+	)
 
 	flags := root.Flags()
 
@@ -60,7 +186,7 @@ This is, of course, faulty.  But sufficient for simple use cases.`,
 		"swapNgram",
 		"s",
 		[]string{},
-		"a comma separated pair of to and from letters. ex -s=th,ð",
+		"a comma separated pair of to and from letters.  Starting from with ^, or ending it with $, only swaps at the start or end of a word.  Repeatable, and applied in order, each to the output of the last. ex -s=th,ð",
 	)
 
 	flags.StringSliceVarP(
@@ -71,19 +197,637 @@ This is, of course, faulty.  But sufficient for simple use cases.`,
 		"a comma separated list of words to remove entirely.  ex -r=the",
 	)
 
+	flags.StringSliceVar(
+		&flagValStopwords,
+		"stopwords",
+		[]string{},
+		"removes the built in stopwords of each language, alongside any -r words, one of: "+strings.Join(stopwordLanguages(), ", ")+".  Repeatable, or comma separated. ex --stopwords=en",
+	)
+
+	flags.StringVar(
+		&flagValRemoveFile,
+		"removeWordsFile",
+		"",
+		"removes the words in the file, one per line, alongside any -r words.  Anything after a # is a comment. ex --removeWordsFile=remove.txt",
+	)
+
+	flags.StringArrayVar(
+		&flagValRemoveRE,
+		"removeRegex",
+		[]string{},
+		"removes every word matching the regex, alongside any -r words.  Repeatable. ex --removeRegex='^[0-9]+$'",
+	)
+
+	flags.StringVar(
+		&flagValSwapFile,
+		"swapFile",
+		"",
+		"reads swaps from the file, one from,to pair per line, after any -s swaps.  Anything after a # is a comment. ex --swapFile=rules.txt",
+	)
+
+	flags.StringArrayVar(
+		&flagValSwapRegex,
+		"swapRegex",
+		[]string{},
+		"a regex,replacement pair of swaps, where the replacement can use the regex's capture groups.  Split at the last ',', and applied after all other swaps.  Repeatable. ex --swapRegex='c([ei]),s$1'",
+	)
+
+	flags.BoolVar(
+		&flagValSwapWhole,
+		"swapWholeWords",
+		false,
+		"swaps only rewrite words that entirely match the swap, instead of every match within a word. ex --swapWholeWords -s=a,æ",
+	)
+
 	flags.BoolVarP(
 		&flagValRemoveHTML,
 		"removeHTML",
 		"w",
 		false,
-		"removes any words that might be part of an html element. ex -removeHTML",
+		"parses inputs as html, counting only text, and dropping all tags, scripts, and styles. ex --removeHTML",
+	)
+
+	flags.BoolVar(
+		&flagValStripped,
+		"countStripped",
+		false,
+		"counts characters stripped during normalization as the letter "+strippedLetter+". ex --countStripped",
+	)
+
+	flags.IntVar(
+		&flagValSample,
+		"sample-words",
+		0,
+		"displays a random sample of N words instead of the top words. ex --sample-words=20",
+	)
+
+	flags.Int64Var(
+		&flagValSeed,
+		"seed",
+		0,
+		"the seed used for any random sampling, for reproducible results. ex --seed=42",
+	)
+
+	flags.Float64Var(
+		&flagValSampleRate,
+		"sample",
+		0,
+		"counts only a random fraction of lines, between 0 and 1, for quick approximate frequencies of huge corpora.  Uses --seed. ex --sample=0.05",
+	)
+
+	flags.StringVar(
+		&flagValLines,
+		"lines",
+		"",
+		"counts only the lines of each file within START:END, numbered from 1.  Both ends are inclusive, and either can be left off. ex --lines=120:4800",
+	)
+
+	flags.IntVar(
+		&flagValSkipLines,
+		"skip-lines",
+		0,
+		"skips the first N lines of each file. ex --skip-lines=40",
+	)
+
+	flags.BoolVar(
+		&flagValStats,
+		"stats",
+		false,
+		"adds a footer with the mean and median word length. ex --stats",
+	)
+
+	flags.BoolVar(
+		&flagValOnlySwap,
+		"letters-only-swapped",
+		false,
+		"prints only the swapped letters as the letters table. ex --letters-only-swapped",
+	)
+
+	flags.DurationVar(
+		&flagValRuntime,
+		"max-runtime",
+		0,
+		"stops counting after the duration, and prints the partial results. ex --max-runtime=30s",
+	)
+
+	flags.StringVar(
+		&flagValRegion,
+		"match-region",
+		"",
+		"only counts the text captured by the regex on each line. ex --match-region='name: (.*)'",
+	)
+
+	flags.StringVar(
+		&flagValFormat,
+		"format",
+		string(formatTable),
+		"the output format, one of: "+strings.Join(formatNames(), ", ")+". ex --format=yaml",
+	)
+
+	flags.StringVarP(
+		&flagValOutput,
+		"output",
+		"o",
+		"",
+		"writes the results to the file instead of stdout. ex -o=results.md",
+	)
+
+	flags.IntVar(
+		&flagValFlushEvery,
+		"flush-every",
+		0,
+		"rewrites the current results to the output file after every N lines. ex --flush-every=10000",
+	)
+
+	flags.BoolVar(
+		&flagValScrabble,
+		"scrabble-score",
+		false,
+		"reports the total and per-word average scrabble score of the letters. ex --scrabble-score",
+	)
+
+	flags.StringVar(
+		&flagValBaseline,
+		"diff-against-baseline-json",
+		"",
+		"fails if any frequency differs from a --save-baseline-json baseline by more than the tolerance.  Drifted frequencies are reported on stderr. ex --diff-against-baseline-json=base.json",
+	)
+
+	flags.StringVar(
+		&flagValSaveBase,
+		"save-baseline-json",
+		"",
+		"writes every word and letter frequency as json, for later runs to compare against with --diff-against-baseline-json. ex --save-baseline-json=base.json",
+	)
+
+	flags.Float64Var(
+		&flagValTolerance,
+		"tolerance",
+		1,
+		"the allowed change, in percentage points, of any frequency from the baseline. ex --tolerance=0.5",
+	)
+
+	flags.BoolVar(
+		&flagValAlnum,
+		"require-alnum",
+		false,
+		"drops any word that contains neither a letter nor a digit. ex --require-alnum",
+	)
+
+	flags.IntSliceVar(
+		&flagValTopWords,
+		"top-words",
+		[]int{10},
+		"a comma separated list of word table sizes, each printed as its own table.  0 prints all words. ex --top-words=10,100",
+	)
+
+	flags.BoolVar(
+		&flagValVowels,
+		"letters-group-vowels",
+		false,
+		"prints vowels before, and separate from, all other letters. ex --letters-group-vowels",
+	)
+
+	flags.StringVar(
+		&flagValSaveState,
+		"save-state",
+		"",
+		"saves all counts to the file after counting.  Files ending in .gz are compressed. ex --save-state=state.json.gz",
+	)
+
+	flags.StringVar(
+		&flagValLoadState,
+		"load-state",
+		"",
+		"loads counts saved by --save-state before counting, and adds onto them. ex --load-state=state.json.gz",
+	)
+
+	flags.BoolVar(
+		&flagValPerLine,
+		"words-per-line",
+		false,
+		"reports the distribution of how many words each line contains. ex --words-per-line",
+	)
+
+	flags.StringVar(
+		&flagValRankLabel,
+		"rank-label",
+		"#",
+		"the header label of the rank column in tables. ex --rank-label=rank",
+	)
+
+	flags.BoolVar(
+		&flagValStrict,
+		"strict",
+		false,
+		"errors on any input that doesn't look like text, instead of skipping it. ex --strict",
+	)
+
+	flags.StringVar(
+		&flagValStripRepl,
+		"strip-replacement",
+		"",
+		"replaces stripped characters with either nothing (merging words) or a space (splitting words). ex --strip-replacement=' '",
+	)
+
+	flags.StringVar(
+		&flagValLineFilter,
+		"line-filter",
+		"",
+		"only counts lines matching the regex. ex --line-filter='^[A-Z]+:'",
+	)
+
+	flags.StringVar(
+		&flagValEmitNorm,
+		"emit-normalized",
+		"",
+		"writes the normalized words of every line to the file, for comparison against the source. ex --emit-normalized=norm.txt",
+	)
+
+	flags.BoolVar(
+		&flagValLocDigits,
+		"locale-digits",
+		false,
+		"keeps digits from all scripts (ex: ٣), instead of only 0-9. ex --locale-digits",
+	)
+
+	flags.BoolVar(
+		&flagValFoldDigits,
+		"fold-digits",
+		false,
+		"converts digits from all scripts to their 0-9 equivalent. ex --fold-digits",
+	)
+
+	flags.BoolVar(
+		&flagValASCIIOnly,
+		"ascii-only",
+		false,
+		"strips all non-ascii letters during normalization, instead of keeping the letters of every script. ex --ascii-only",
+	)
+
+	flags.BoolVar(
+		&flagValCaseSens,
+		"case-sensitive",
+		false,
+		"keeps the case of letters, instead of lowercasing all text.  Swaps match regardless of case, and keep the casing of the text they replace (ex: with -s=th,ð, Th becomes Ð).  Removed words still match regardless of case. ex --case-sensitive",
+	)
+
+	flags.StringVar(
+		&flagValKeepChars,
+		"keep-chars",
+		"",
+		"the regex character class of characters kept during normalization, in place of the letters of every script and 0-9.  Text is lowercased first, unless --case-sensitive. ex --keep-chars=\"a-zåäö'\"",
+	)
+
+	flags.StringVar(
+		&flagValUniNorm,
+		"unicode-norm",
+		"",
+		"converts text into a unicode normalization form, one of: NFC, NFD, NFKC, NFKD, so that composed and decomposed letters count the same.  The K forms also fold compatibility characters (ex: ﬁ to fi). ex --unicode-norm=NFC",
+	)
+
+	flags.BoolVar(
+		&flagValFoldMarks,
+		"fold-diacritics",
+		false,
+		"maps accented letters to their base letter (ex: é to e, ñ to n), for accent-insensitive counts. ex --fold-diacritics",
+	)
+
+	flags.BoolVar(
+		&flagValApostrophe,
+		"keep-apostrophes",
+		false,
+		"keeps apostrophes within words, so that contractions stay one word (ex: don't, instead of dont). ex --keep-apostrophes",
+	)
+
+	flags.BoolVar(
+		&flagValSplitContr,
+		"split-contractions",
+		false,
+		"expands contractions into the words they stand for (ex: don't into do and not).  Possessive and contracted 's are left alone. ex --split-contractions",
+	)
+
+	flags.StringVar(
+		&flagValHyphens,
+		"hyphens",
+		string(hyphensJoin),
+		"what happens to hyphens within words, one of: "+strings.Join(hyphenModeNames(), ", ")+".  join counts well-known as wellknown, split as well and known, and keep as well-known. ex --hyphens=split",
+	)
+
+	flags.BoolVar(
+		&flagValSplitIdent,
+		"split-identifiers",
+		false,
+		"splits camelCase and snake_case identifiers into their words, for counting source code. ex --split-identifiers",
+	)
+
+	flags.StringVar(
+		&flagValSegmentCJK,
+		"segment-cjk",
+		"",
+		"segments chinese and japanese text into the words of the dictionary file, since those scripts don't space their words.  Otherwise each unspaced run counts as one giant word.  The dictionary holds one word per line, each optionally followed by its frequency (ex: jieba's dict.txt).  Characters missing from the dictionary count as their own words. ex --segment-cjk=dict.txt",
+	)
+
+	flags.BoolVar(
+		&flagValEmoji,
+		"emoji",
+		false,
+		"keeps emoji and other symbols during normalization, counting each emoji sequence (ex: 👍🏽) as a single letter. ex --emoji",
+	)
+
+	flags.StringVar(
+		&flagValTokenizer,
+		"tokenizer",
+		defaultTokenizer,
+		"how lines get split into words, one of: "+strings.Join(tokenizerNames(), ", ")+".  regex applies all of the normalization flags, while whitespace only splits on spaces. ex --tokenizer=whitespace",
+	)
+
+	flags.IntVar(
+		&flagValMinWordLen,
+		"min-word-len",
+		0,
+		"skips words with fewer than N letters, in both the words and letters. ex --min-word-len=2",
+	)
+
+	flags.IntVar(
+		&flagValMaxWordLen,
+		"max-word-len",
+		0,
+		"skips words with more than N letters, in both the words and letters.  0 allows any length. ex --max-word-len=30",
+	)
+
+	flags.StringVar(
+		&flagValNumbers,
+		"numbers",
+		string(numbersKeep),
+		"how words made only of digits get counted, one of: "+strings.Join(numberModeNames(), ", ")+".  drop and separate keep them out of the words and letters, and separate counts them in a numbers table instead. ex --numbers=drop",
+	)
+
+	flags.BoolVar(
+		&flagValSwapRatio,
+		"swap-ratio",
+		false,
+		"adds a footer with the percent of letters changed by swaps. ex --swap-ratio",
+	)
+
+	flags.BoolVar(
+		&flagValBlocks,
+		"unicode-blocks",
+		false,
+		"reports the count of letters within each unicode block. ex --unicode-blocks",
+	)
+
+	flags.BoolVar(
+		&flagValPerFile,
+		"per-file",
+		false,
+		"reports the words and letters of each file, in addition to the aggregate. ex --per-file",
+	)
+
+	flags.BoolVar(
+		&flagValInterleave,
+		"interleave-output",
+		false,
+		"in per-file mode, prints each file's tables as soon as the file is counted. ex --interleave-output",
+	)
+
+	flags.IntVar(
+		&flagValDistinct,
+		"top-distinct-freqs",
+		0,
+		"displays every word whose count is among the K highest distinct counts, keeping all ties. ex --top-distinct-freqs=3",
+	)
+
+	flags.BoolVar(
+		&flagValMDLinks,
+		"strip-markdown-links",
+		false,
+		"removes markdown images, and the urls of markdown links, keeping the link text. ex --strip-markdown-links",
+	)
+
+	flags.BoolVar(
+		&flagValGeminates,
+		"geminates",
+		false,
+		"reports the count of doubled letters (ex: ll, ee) within words. ex --geminates",
+	)
+
+	flags.BoolVar(
+		&flagValPercentile,
+		"percentile",
+		false,
+		"reports the word counts at the 50th, 90th, and 99th percentiles of all word occurrences. ex --percentile",
+	)
+
+	flags.BoolVar(
+		&flagValNormFiles,
+		"normalize-per-file",
+		false,
+		"weights each file equally in the aggregate, so that large files don't dominate small ones. ex --normalize-per-file",
+	)
+
+	flags.BoolVar(
+		&flagValRmAfterSwp,
+		"remove-after-swap",
+		false,
+		"compares the swapped form of each word, instead of the raw word, against the removed words. ex --remove-after-swap",
+	)
+
+	flags.StringVar(
+		&flagValTrigrams,
+		"trigram-profile",
+		"",
+		"writes the normalized frequencies of the most common character trigrams to the file as json, for language identification. ex --trigram-profile=en.json",
+	)
+
+	flags.StringVar(
+		&flagValDelimiter,
+		"delimiter",
+		"comma",
+		"the field separator of --format=csv, either a single character or one of: comma, tab, semicolon, pipe. ex --delimiter=tab",
+	)
+
+	flags.StringVar(
+		&flagValVocabAfter,
+		"vocab-after",
+		"",
+		"only counts words that first appear after the first line matching the regex. ex --vocab-after='^CHAPTER VII'",
+	)
+
+	flags.StringSliceVar(
+		&flagValDenyClass,
+		"deny-class",
+		[]string{},
+		"a comma separated list of unicode classes (ex: Symbol, Sm, Greek) whose characters are never counted as letters. ex --deny-class=Punctuation,Symbol",
+	)
+
+	flags.IntVar(
+		&flagValRarity,
+		"letter-rarity",
+		0,
+		"reports the N words whose letters are, on average, the least frequent of all letters. ex --letter-rarity=20",
+	)
+
+	flags.BoolVar(
+		&flagValOnlyChange,
+		"only-changed-by-swap",
+		false,
+		"only displays words that were changed by a swap in the word tables. ex --only-changed-by-swap",
+	)
+
+	flags.BoolVar(
+		&flagValCommonWord,
+		"longest-common-word",
+		false,
+		"adds a footer with the longest word that appears in every file. ex --longest-common-word",
+	)
+
+	flags.StringVar(
+		&flagValReportFile,
+		"report-words-file",
+		"",
+		"reports the counts of only the words listed in the file, one per line, including words that never appeared. ex --report-words-file=watchlist.txt",
+	)
+
+	flags.BoolVar(
+		&flagValGrowth,
+		"measure-unique-growth-per-file",
+		false,
+		"reports how many new unique words each file added beyond the files counted before it. ex --measure-unique-growth-per-file",
+	)
+
+	flags.IntVar(
+		&flagValSIPrecise,
+		"si-precision",
+		1,
+		"the count of decimal places shown in SI prefixed counts (ex: 1.23k). ex --si-precision=2",
+	)
+
+	flags.StringVar(
+		&flagValOrder,
+		"order",
+		string(orderNone),
+		"the order files are counted in, one of: "+strings.Join(fileOrderNames(), ", ")+".  none keeps the argument order. ex --order=size",
+	)
+
+	flags.StringVar(
+		&flagValHideRegex,
+		"hide-regex",
+		"",
+		"hides words matching the regex from the word tables, without removing them from any counts or percentages. ex --hide-regex='^(the|and|of)$'",
+	)
+
+	flags.BoolVar(
+		&flagValOnsets,
+		"initial-bigrams",
+		false,
+		"reports the count of the first two letters (the onset) of each word. ex --initial-bigrams",
+	)
+
+	flags.IntVar(
+		&flagValAutoApprox,
+		"auto-approx-at",
+		0,
+		"once N unique words are counted, counts any new words approximately, in fixed memory.  New words won't appear in the word tables. ex --auto-approx-at=1000000",
+	)
+
+	flags.StringVar(
+		&flagValFromFile,
+		"fromFile",
+		"",
+		"reads the inputs to count from the file, one per line, after any inputs given as arguments.  Blank lines, and lines starting with #, are skipped. ex --fromFile=corpora.txt",
+	)
+
+	flags.StringSliceVar(
+		&flagValExt,
+		"ext",
+		[]string{".txt"},
+		"the extensions of files to count.  Repeatable, or comma separated. ex --ext=.md,.log",
+	)
+
+	flags.BoolVar(
+		&flagValAnyFile,
+		"any-file",
+		false,
+		"counts every file, regardless of its extension. ex --any-file",
+	)
+
+	flags.StringSliceVar(
+		&flagValExclude,
+		"exclude",
+		[]string{},
+		"globs of files and directories to skip, matched against both the path and the name.  Repeatable, or comma separated. ex --exclude='*_index.txt,LICENSE.txt'",
+	)
+
+	flags.BoolVar(
+		&flagValGutenberg,
+		"strip-gutenberg",
+		false,
+		"only counts the text between the project gutenberg *** START OF and *** END OF markers of each file. ex --strip-gutenberg",
+	)
+
+	flags.BoolVar(
+		&flagValMarkdown,
+		"markdown",
+		false,
+		"reads inputs as markdown, counting only prose: code blocks, inline code, urls, heading markers, and emphasis are all dropped. ex --markdown",
+	)
+
+	flags.StringVar(
+		&flagValCSVColumn,
+		"csv-column",
+		"",
+		"counts only one column of .csv and .tsv inputs, selected by its header name, or by its 0-based index.  Also counts .csv and .tsv files found in directories. ex --csv-column=review_text",
+	)
+
+	flags.StringVar(
+		&flagValJSONLField,
+		"jsonl-field",
+		"",
+		"counts only one field of each record in .jsonl and .ndjson inputs, addressed by a dot-path.  Also counts .jsonl and .ndjson files found in directories. ex --jsonl-field=data.text",
+	)
+
+	flags.BoolVar(
+		&flagValLatex,
+		"latex",
+		false,
+		"reads inputs as latex source, counting only prose: comments, math, verbatim text, and commands are all dropped, though the arguments of most commands (ex: section titles) are kept. ex --latex",
+	)
+
+	flags.BoolVar(
+		&flagValSkipQuoted,
+		"skip-quoted",
+		false,
+		"skips quoted reply lines (ex: > on monday, you wrote) in the message bodies of .mbox inputs. ex --skip-quoted",
+	)
+
+	flags.BoolVar(
+		&flagValWatch,
+		"watch",
+		false,
+		"after counting, keeps watching the inputs, and recounts and reprints the results whenever any of them change.  Only local files can be watched. ex --watch",
 	)
 
 	return root
 }
 
+// initLogging seeds the logger in the command context, now that the
+// logging flags have been parsed.
+func initLogging(cmd *cobra.Command, args []string) error {
+	set, err := logSettings(flagValLogSink, flagValLogFile)
+	if err != nil {
+		return cluerr.Wrap(err, "configuring logging")
+	}
+
+	cmd.SetContext(clog.Init(cmd.Context(), set))
+
+	return nil
+}
+
 func main() {
-	ctx := clog.Init(context.Background(), clog.Settings{})
+	// the logger gets initialized after flags are parsed.
+	// See initLogging.
+	ctx := context.Background()
 
 	defer func() {
 		clog.Flush(ctx)
@@ -111,6 +855,11 @@ type stats struct {
 	// text stats with both swapped and removed parts.
 	countBoth *xsync.Counter
 	both      *xsync.Map[string, *xsync.Counter]
+
+	// the count of raw parts replaced by swaps.  Tracked apart from
+	// the swapped totals, since swaps can grow the text as easily
+	// as they shrink it.
+	countChanged *xsync.Counter
 }
 
 func makeStats() stats {
@@ -123,107 +872,1280 @@ func makeStats() stats {
 		removed:      xsync.NewMap[string, *xsync.Counter](),
 		countBoth:    xsync.NewCounter(),
 		both:         xsync.NewMap[string, *xsync.Counter](),
+		countChanged: xsync.NewCounter(),
 	}
 }
 
 type nGramSwap struct {
 	from, to string
+	// when non-nil, from is a regex, and to can refer to its
+	// capture groups (ex: $1).
+	re *regexp.Regexp
+	// when true, only words that entirely match from get swapped.
+	whole bool
+	// when true, re matches regardless of case, and replacements
+	// keep the casing of the text they replace.
+	preserveCase bool
 }
 
 type handler struct {
 	removeWords map[string]struct{}
-	swapNGrams  []nGramSwap
-	removeHTML  bool
-	words       stats
-	letters     stats
+	// words matching any of these are removed, the same as
+	// removeWords.
+	removeRegexes []*regexp.Regexp
+	// applied in order, each to the output of the last: -s swaps,
+	// then --swapFile swaps, then --swapRegex swaps.
+	swapNGrams    []nGramSwap
+	norm          normalizeOpts
+	tokenizer     tokenizer
+	countStripped bool
+	sampleWords   int
+	seed          int64
+	// when > 0, only this fraction of lines, chosen by sampler,
+	// is counted.
+	sampleRate float64
+	sampler    *rand.Rand
+	// the lines of each file that get counted.
+	lines         lineRange
+	lengthStats   bool
+	onlySwapped   bool
+	maxRuntime    time.Duration
+	matchRegion   *regexp.Regexp
+	lineFilter    *regexp.Regexp
+	format        outputFormat
+	output        string
+	flushEvery    int
+	linesSeen     int
+	scrabble      bool
+	baseline      string
+	tolerance     float64
+	saveBaseline  string
+	topWords      []int
+	groupVowels   bool
+	saveStateTo   string
+	loadStateFrom string
+	perLine       bool
+	rankLabel     string
+	strict        bool
+	emitPath      string
+	swapRatio     bool
+	blocks        bool
+	perFile       bool
+	interleave    bool
+	// where interleaved file tables get written, ahead of the
+	// final report.
+	reportTo      io.Writer
+	distinctFreqs int
+	// whether geminate stats should be collected
+	countGeminates bool
+	geminates      stats
+	percentiles    bool
+	// whether each file contributes equally to the aggregate stats.
+	normalizePerFile bool
+	// whether removal matches the swapped form of a word, rather
+	// than the raw word.  Either way, the raw and swapped forms are
+	// both removed together.
+	removeAfterSwap bool
+	// when populated, the trigram profile gets written to this file.
+	trigramPath  string
+	trigrams     *xsync.Map[string, *xsync.Counter]
+	trigramCount *xsync.Counter
+	// the field separator of csv output.
+	delimiter rune
+	// when non-nil, only words that first appear after the first
+	// line matching the regex are counted.  Every word seen before
+	// that line is held in vocabBefore.
+	vocabAfter  *regexp.Regexp
+	pastMarker  bool
+	vocabBefore map[string]struct{}
+	// characters within these classes are excluded from the letters.
+	denyClasses []*unicode.RangeTable
+	// the count of rarest-lettered words to report.  0 skips the report.
+	letterRarity int
+	// when non-nil, word tables only display words changed by swaps.
+	changed *changedWords
+	// whether to report the longest word shared by every file.
+	commonWord bool
+	// when populated, the words listed in this file get their own table.
+	reportWordsFile string
+	reportWords     []string
+	// whether to report the new unique words added by each file.
+	uniqueGrowth bool
+	growthSeen   map[string]struct{}
+	growth       []fileGrowth
+	// the order files are counted in.
+	order fileOrder
+	// when non-nil, matching words are hidden from the word tables.
+	hideWords *regexp.Regexp
+	// whether word-initial bigram stats should be collected
+	countOnsets bool
+	onsets      stats
+	// how purely numeric words get counted.
+	numbers     numberMode
+	numberStats stats
+	// words outside of these lengths, in letters, are skipped.
+	// 0 leaves the bound open.
+	minWordLen int
+	maxWordLen int
+	// once this many unique words are counted, new words are counted
+	// in the sketch instead.  0 never approximates.
+	autoApproxAt int
+	sketch       *countMinSketch
+	// when populated, inputs are also read from this file.
+	manifest string
+	// decides which files get counted.
+	inputs inputMatcher
+	// whether the inputs get recounted whenever they change.
+	watch bool
+	// whether to only count text within project gutenberg markers.
+	stripGutenberg bool
+	// whether quoted reply lines in mbox messages are skipped.
+	skipQuoted bool
+	// whether inputs get parsed as html, keeping only their text.
+	removeHTML bool
+	// when non-nil, only this column of .csv and .tsv inputs
+	// is counted.
+	tableColumn *tableColumn
+	// when populated, only this field of the records in .jsonl
+	// inputs is counted.
+	jsonlField []string
+	// the stats of the file currently being counted, in per-file mode.
+	file *fileCounts
+	// the stats of every counted file, in per-file mode.
+	files []*fileCounts
+	// when non-nil, receives the normalized text of every line.
+	emitter *bufio.Writer
+	// line length (in words) -> count of lines
+	wordsPerLine *xsync.Map[int, *xsync.Counter]
+	words        stats
+	letters      stats
 }
 
 func newHandler() *handler {
 	return &handler{
-		removeWords: map[string]struct{}{},
-		swapNGrams:  []nGramSwap{},
-		removeHTML:  false,
-		words:       makeStats(),
-		letters:     makeStats(),
+		removeWords:      map[string]struct{}{},
+		removeRegexes:    nil,
+		swapNGrams:       []nGramSwap{},
+		norm:             normalizeOpts{},
+		tokenizer:        regexTokenizer{},
+		countStripped:    false,
+		sampleWords:      0,
+		seed:             0,
+		sampleRate:       0,
+		sampler:          nil,
+		lines:            lineRange{},
+		lengthStats:      false,
+		onlySwapped:      false,
+		maxRuntime:       0,
+		matchRegion:      nil,
+		lineFilter:       nil,
+		format:           formatTable,
+		output:           "",
+		flushEvery:       0,
+		linesSeen:        0,
+		scrabble:         false,
+		baseline:         "",
+		tolerance:        1,
+		topWords:         []int{10},
+		groupVowels:      false,
+		saveStateTo:      "",
+		loadStateFrom:    "",
+		perLine:          false,
+		rankLabel:        "#",
+		strict:           false,
+		emitPath:         "",
+		swapRatio:        false,
+		blocks:           false,
+		perFile:          false,
+		interleave:       false,
+		distinctFreqs:    0,
+		countGeminates:   false,
+		geminates:        makeStats(),
+		percentiles:      false,
+		normalizePerFile: false,
+		removeAfterSwap:  false,
+		trigramPath:      "",
+		trigrams:         xsync.NewMap[string, *xsync.Counter](),
+		trigramCount:     xsync.NewCounter(),
+		delimiter:        ',',
+		vocabAfter:       nil,
+		pastMarker:       false,
+		vocabBefore:      map[string]struct{}{},
+		denyClasses:      nil,
+		letterRarity:     0,
+		changed:          nil,
+		commonWord:       false,
+		reportWordsFile:  "",
+		reportWords:      nil,
+		uniqueGrowth:     false,
+		growthSeen:       map[string]struct{}{},
+		growth:           []fileGrowth{},
+		order:            orderNone,
+		hideWords:        nil,
+		countOnsets:      false,
+		onsets:           makeStats(),
+		numbers:          numbersKeep,
+		numberStats:      makeStats(),
+		minWordLen:       0,
+		maxWordLen:       0,
+		autoApproxAt:     0,
+		sketch:           nil,
+		manifest:         "",
+		inputs:           inputMatcher{exts: []string{".txt"}},
+		watch:            false,
+		stripGutenberg:   false,
+		skipQuoted:       false,
+		removeHTML:       false,
+		tableColumn:      nil,
+		jsonlField:       nil,
+		file:             nil,
+		files:            []*fileCounts{},
+		emitter:          nil,
+		wordsPerLine:     xsync.NewMap[int, *xsync.Counter](),
+		words:            makeStats(),
+		letters:          makeStats(),
+		reportTo:         os.Stdout,
 	}
 }
 
 // post processing of flag inputs after cobra has engaged the command
 // and processed the flags.  This sets everything up for usage in scanning.
 func (h *handler) parseFlags() error {
-	for _, swap := range flagValSwap {
-		parts := strings.Split(
-			strings.ToLower(swap),
-			",",
-		)
-
-		if len(parts) != 2 {
-			return cluerr.New("improperly formed swapNGram: only one ',' expected").
-				With("input", swap)
-		}
-
-		h.swapNGrams = append(h.swapNGrams, nGramSwap{
-			from: parts[0],
-			to:   parts[1],
-		})
+	// in order, since later steps can depend on earlier ones.
+	steps := []func() error{
+		h.parseSwaps,
+		h.parseNormalize,
+		h.parseWordFilters,
+		h.parseSampling,
+		h.parseScanning,
+		h.parseReports,
+		h.parseInputs,
+		h.parseOutput,
+		h.requireSwaps,
 	}
 
-	for _, remove := range flagValRemove {
-		h.removeWords[remove] = struct{}{}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
 	}
 
-	h.removeHTML = flagValRemoveHTML
-
 	return nil
 }
 
-func (h *handler) run(cmd *cobra.Command, args []string) error {
-	ctx := cmd.Context()
+// parseSwaps collects the swaps of every swap flag, in the order
+// they get applied.
+func (h *handler) parseSwaps() error {
+	for _, s := range flagValSwap {
+		swap, err := parseSwap(s)
+		if err != nil {
+			return err
+		}
 
-	if err := h.parseFlags(); err != nil {
-		return cluerr.WrapWC(ctx, err, "parsing flags")
+		h.swapNGrams = append(h.swapNGrams, swap)
 	}
 
-	// precheck all files for validity
-	for _, arg := range args {
-		if !strings.HasSuffix(arg, ".txt") {
-			return cluerr.NewWC(ctx, "must be .txt: "+arg)
+	// file swaps follow the flag swaps.
+	if len(flagValSwapFile) > 0 {
+		swaps, err := readSwapFile(flagValSwapFile)
+		if err != nil {
+			return cluerr.Wrap(err, "loading swaps: "+flagValSwapFile)
 		}
 
-		_, err := os.Stat(arg)
+		h.swapNGrams = append(h.swapNGrams, swaps...)
+	}
+
+	for _, s := range flagValSwapRegex {
+		swap, err := parseRegexSwap(s)
 		if err != nil {
-			return cluerr.WrapWC(ctx, err, "checking file: "+arg)
+			return err
 		}
+
+		h.swapNGrams = append(h.swapNGrams, swap)
 	}
 
-	// aggregate all stats per file
-	for _, arg := range args {
-		if err := h.runFile(ctx, arg); err != nil {
-			return cluerr.Wrap(err, "executing command")
+	// before whole words, which anchors the case-insensitive regex.
+	if flagValCaseSens {
+		for i, swap := range h.swapNGrams {
+			h.swapNGrams[i] = swap.matchingCase()
 		}
 	}
 
-	print(h.words, "words", 10, os.Stdout)
-
-	fmt.Println(" ")
-
-	print(h.letters, "letters", 0, os.Stdout)
+	if flagValSwapWhole {
+		for i, swap := range h.swapNGrams {
+			h.swapNGrams[i] = swap.wholeWord()
+		}
+	}
 
 	return nil
 }
 
-func (h *handler) runFile(
-	ctx context.Context,
+// parseNormalize configures how lines get normalized into words,
+// and the tokenizer that does so.
+func (h *handler) parseNormalize() error {
+	h.removeHTML = flagValRemoveHTML
+	h.norm.requireAlnum = flagValAlnum
+
+	if flagValStripRepl != "" && flagValStripRepl != " " {
+		return cluerr.New("strip-replacement must be empty or a single space").
+			With("input", flagValStripRepl)
+	}
+
+	h.norm.stripReplacement = flagValStripRepl
+	h.norm.localeDigits = flagValLocDigits
+	h.norm.foldDigits = flagValFoldDigits
+	h.norm.asciiOnly = flagValASCIIOnly
+	h.norm.caseSensitive = flagValCaseSens
+
+	if len(flagValKeepChars) > 0 {
+		class, err := parseKeepChars(flagValKeepChars)
+		if err != nil {
+			return err
+		}
+
+		h.norm.keepClass = class
+	}
+
+	if len(flagValUniNorm) > 0 {
+		form, err := parseUnicodeForm(flagValUniNorm)
+		if err != nil {
+			return err
+		}
+
+		h.norm.unicodeNorm = true
+		h.norm.unicodeForm = form
+	}
+
+	h.norm.foldDiacritics = flagValFoldMarks
+	h.norm.keepApostrophes = flagValApostrophe
+	h.norm.splitContractions = flagValSplitContr
+	h.norm.splitIdentifiers = flagValSplitIdent
+
+	if len(flagValSegmentCJK) > 0 {
+		dict, err := readCJKDict(flagValSegmentCJK)
+		if err != nil {
+			return cluerr.Wrap(err, "loading cjk dictionary: "+flagValSegmentCJK)
+		}
+
+		h.norm.cjkDict = dict
+	}
+
+	h.norm.emoji = flagValEmoji
+	h.norm.hyphens = hyphenMode(flagValHyphens)
+
+	if !slices.Contains(hyphenModes, h.norm.hyphens) {
+		return cluerr.New("unsupported hyphens mode").
+			With("input", flagValHyphens)
+	}
+
+	h.norm.stripMarkdownLinks = flagValMDLinks
+	h.norm.markdown = flagValMarkdown
+	h.norm.latex = flagValLatex
+	h.norm.compile()
+
+	newTokenizer, ok := tokenizers[flagValTokenizer]
+	if !ok {
+		return cluerr.New("unsupported tokenizer").
+			With("input", flagValTokenizer)
+	}
+
+	h.tokenizer = newTokenizer(h.norm)
+
+	return nil
+}
+
+// parseWordFilters configures which words get removed or dropped.
+// Removed words get normalized by the tokenizer, so this follows
+// parseNormalize.
+func (h *handler) parseWordFilters() error {
+	h.removeAfterSwap = flagValRmAfterSwp
+
+	// lowercased, since words are lowercased before matching
+	// against the removed words, even in case-sensitive mode.
+	for _, remove := range flagValRemove {
+		h.removeWords[strings.ToLower(remove)] = struct{}{}
+	}
+
+	for _, lang := range flagValStopwords {
+		stopwords, err := readStopwords(lang)
+		if err != nil {
+			return err
+		}
+
+		h.addRemoveWords(stopwords)
+	}
+
+	for _, pattern := range flagValRemoveRE {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return cluerr.Wrap(err, "compiling removeRegex").
+				With("input", pattern)
+		}
+
+		h.removeRegexes = append(h.removeRegexes, re)
+	}
+
+	if len(flagValRemoveFile) > 0 {
+		words, err := readWordList(flagValRemoveFile)
+		if err != nil {
+			return cluerr.Wrap(err, "loading remove words: "+flagValRemoveFile)
+		}
+
+		h.addRemoveWords(words)
+	}
+
+	if flagValMinWordLen < 0 {
+		return cluerr.New("min-word-len cannot be negative").
+			With("input", flagValMinWordLen)
+	}
+
+	if flagValMaxWordLen < 0 {
+		return cluerr.New("max-word-len cannot be negative").
+			With("input", flagValMaxWordLen)
+	}
+
+	if flagValMaxWordLen > 0 && flagValMaxWordLen < flagValMinWordLen {
+		return cluerr.New("max-word-len cannot be less than min-word-len").
+			With("min", flagValMinWordLen, "max", flagValMaxWordLen)
+	}
+
+	h.minWordLen = flagValMinWordLen
+	h.maxWordLen = flagValMaxWordLen
+	h.numbers = numberMode(flagValNumbers)
+
+	if !slices.Contains(numberModes, h.numbers) {
+		return cluerr.New("unsupported numbers mode").
+			With("input", flagValNumbers)
+	}
+
+	return nil
+}
+
+// parseSampling configures the random sampling of lines and words.
+func (h *handler) parseSampling() error {
+	if flagValSample < 0 {
+		return cluerr.New("sample-words cannot be negative").
+			With("input", flagValSample)
+	}
+
+	h.sampleWords = flagValSample
+	h.seed = flagValSeed
+
+	if flagValSampleRate < 0 || flagValSampleRate > 1 {
+		return cluerr.New("sample must be between 0 and 1").
+			With("input", flagValSampleRate)
+	}
+
+	if flagValSampleRate > 0 && flagValSampleRate < 1 {
+		h.sampleRate = flagValSampleRate
+		h.sampler = rand.New(rand.NewPCG(uint64(h.seed), 0))
+	}
+
+	return nil
+}
+
+// parseScanning configures which lines of each input, and which
+// text within them, get counted.
+func (h *handler) parseScanning() error {
+	h.stripGutenberg = flagValGutenberg
+	h.skipQuoted = flagValSkipQuoted
+
+	lines, err := parseLineRange(flagValLines)
+	if err != nil {
+		return err
+	}
+
+	if flagValSkipLines < 0 {
+		return cluerr.New("skip-lines cannot be negative").
+			With("input", flagValSkipLines)
+	}
+
+	h.lines = lines.skip(flagValSkipLines)
+
+	if flagValRuntime < 0 {
+		return cluerr.New("max-runtime cannot be negative").
+			With("input", flagValRuntime)
+	}
+
+	h.maxRuntime = flagValRuntime
+
+	if len(flagValRegion) > 0 {
+		re, err := regexp.Compile(flagValRegion)
+		if err != nil {
+			return cluerr.Wrap(err, "compiling match-region").
+				With("input", flagValRegion)
+		}
+
+		h.matchRegion = re
+	}
+
+	if len(flagValLineFilter) > 0 {
+		re, err := regexp.Compile(flagValLineFilter)
+		if err != nil {
+			return cluerr.Wrap(err, "compiling line-filter").
+				With("input", flagValLineFilter)
+		}
+
+		h.lineFilter = re
+	}
+
+	if len(flagValVocabAfter) > 0 {
+		re, err := regexp.Compile(flagValVocabAfter)
+		if err != nil {
+			return cluerr.Wrap(err, "compiling vocab-after").
+				With("input", flagValVocabAfter)
+		}
+
+		h.vocabAfter = re
+	}
+
+	return nil
+}
+
+// parseReports configures the tables and reports that get printed.
+func (h *handler) parseReports() error {
+	h.countStripped = flagValStripped
+	h.lengthStats = flagValStats
+	h.onlySwapped = flagValOnlySwap
+	h.scrabble = flagValScrabble
+	h.baseline = flagValBaseline
+	h.saveBaseline = flagValSaveBase
+	h.groupVowels = flagValVowels
+	h.perLine = flagValPerLine
+	h.rankLabel = flagValRankLabel
+	h.swapRatio = flagValSwapRatio
+	h.blocks = flagValBlocks
+	h.countGeminates = flagValGeminates
+	h.countOnsets = flagValOnsets
+	h.percentiles = flagValPercentile
+	h.trigramPath = flagValTrigrams
+
+	if len(flagValHideRegex) > 0 {
+		re, err := regexp.Compile(flagValHideRegex)
+		if err != nil {
+			return cluerr.Wrap(err, "compiling hide-regex").
+				With("input", flagValHideRegex)
+		}
+
+		h.hideWords = re
+	}
+
+	if len(flagValDenyClass) > 0 {
+		tables, err := classTables(flagValDenyClass)
+		if err != nil {
+			return cluerr.Wrap(err, "parsing deny-class")
+		}
+
+		h.denyClasses = tables
+	}
+
+	if flagValRarity < 0 {
+		return cluerr.New("letter-rarity cannot be negative").
+			With("input", flagValRarity)
+	}
+
+	h.letterRarity = flagValRarity
+	h.commonWord = flagValCommonWord
+	h.reportWordsFile = flagValReportFile
+	h.uniqueGrowth = flagValGrowth
+
+	if flagValSIPrecise < 0 {
+		return cluerr.New("si-precision cannot be negative").
+			With("input", flagValSIPrecise)
+	}
+
+	siPrecision = flagValSIPrecise
+
+	if flagValOnlyChange {
+		h.changed = &changedWords{
+			raw:     map[string]struct{}{},
+			swapped: map[string]struct{}{},
+		}
+	}
+
+	if flagValTolerance < 0 {
+		return cluerr.New("tolerance cannot be negative").
+			With("input", flagValTolerance)
+	}
+
+	h.tolerance = flagValTolerance
+
+	for _, top := range flagValTopWords {
+		if top < 0 {
+			return cluerr.New("top-words cannot be negative").
+				With("input", top)
+		}
+	}
+
+	if len(flagValTopWords) > 0 {
+		h.topWords = flagValTopWords
+	}
+
+	if flagValDistinct < 0 {
+		return cluerr.New("top-distinct-freqs cannot be negative").
+			With("input", flagValDistinct)
+	}
+
+	h.distinctFreqs = flagValDistinct
+
+	if flagValAutoApprox < 0 {
+		return cluerr.New("auto-approx-at cannot be negative").
+			With("input", flagValAutoApprox)
+	}
+
+	h.autoApproxAt = flagValAutoApprox
+
+	return nil
+}
+
+// parseInputs configures which inputs get counted, and in what order.
+func (h *handler) parseInputs() error {
+	h.manifest = flagValFromFile
+	h.watch = flagValWatch
+	h.strict = flagValStrict
+
+	h.order = fileOrder(flagValOrder)
+
+	if !slices.Contains(fileOrders, h.order) {
+		return cluerr.New("unsupported order").
+			With("input", flagValOrder)
+	}
+
+	h.inputs = inputMatcher{anyFile: flagValAnyFile}
+
+	for _, pattern := range flagValExclude {
+		if !doublestar.ValidatePattern(pattern) {
+			return cluerr.New("invalid exclude glob").
+				With("input", pattern)
+		}
+
+		h.inputs.exclude = append(h.inputs.exclude, pattern)
+	}
+
+	for _, ext := range flagValExt {
+		ext = strings.TrimSpace(ext)
+
+		if len(ext) == 0 {
+			return cluerr.New("ext cannot be empty; use --any-file to count files without extensions")
+		}
+
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+
+		h.inputs.exts = append(h.inputs.exts, ext)
+	}
+
+	if len(flagValCSVColumn) > 0 {
+		col, err := parseTableColumn(flagValCSVColumn)
+		if err != nil {
+			return err
+		}
+
+		h.tableColumn = &col
+
+		for ext := range tableDelimiters {
+			if !slices.Contains(h.inputs.exts, ext) {
+				h.inputs.exts = append(h.inputs.exts, ext)
+			}
+		}
+	}
+
+	if len(flagValJSONLField) > 0 {
+		field, err := parseFieldPath(flagValJSONLField)
+		if err != nil {
+			return err
+		}
+
+		h.jsonlField = field
+
+		for _, ext := range jsonlExts {
+			if !slices.Contains(h.inputs.exts, ext) {
+				h.inputs.exts = append(h.inputs.exts, ext)
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseOutput configures where, and in what format, results get
+// written.
+func (h *handler) parseOutput() error {
+	h.saveStateTo = flagValSaveState
+	h.loadStateFrom = flagValLoadState
+	h.emitPath = flagValEmitNorm
+	h.normalizePerFile = flagValNormFiles
+
+	h.output = flagValOutput
+
+	if flagValFlushEvery < 0 {
+		return cluerr.New("flush-every cannot be negative").
+			With("input", flagValFlushEvery)
+	}
+
+	if flagValFlushEvery > 0 && len(h.output) == 0 {
+		return cluerr.New("flush-every requires an output file")
+	}
+
+	h.flushEvery = flagValFlushEvery
+
+	if flagValInterleave && !flagValPerFile {
+		return cluerr.New("interleave-output requires per-file")
+	}
+
+	h.perFile = flagValPerFile
+	h.interleave = flagValInterleave
+
+	delimiter, err := parseDelimiter(flagValDelimiter)
+	if err != nil {
+		return err
+	}
+
+	h.delimiter = delimiter
+
+	h.format = outputFormat(flagValFormat)
+
+	if !slices.Contains(formats, h.format) {
+		return cluerr.New("unsupported format").
+			With("input", flagValFormat)
+	}
+
+	if h.interleave && h.format != formatTable {
+		return cluerr.New("interleave-output only supports the table format")
+	}
+
+	// flushes replace the output, which would drop the interleaved
+	// file tables written ahead of the final report.
+	if h.interleave && h.flushEvery > 0 {
+		return cluerr.New("interleave-output can't be combined with flush-every")
+	}
+
+	return nil
+}
+
+// requireSwaps errors if any report that only describes swapped
+// text was requested without configuring any swaps.
+func (h *handler) requireSwaps() error {
+	if len(h.swapNGrams) > 0 {
+		return nil
+	}
+
+	swapDependent := []struct {
+		flag string
+		set  bool
+	}{
+		{"letters-only-swapped", h.onlySwapped},
+		{"swap-ratio", h.swapRatio},
+		{"only-changed-by-swap", h.changed != nil},
+	}
+
+	for _, sd := range swapDependent {
+		if sd.set {
+			return cluerr.New(sd.flag + " requires at least one swapNgram")
+		}
+	}
+
+	return nil
+}
+
+func (h *handler) run(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if err := h.parseFlags(); err != nil {
+		return cluerr.WrapWC(ctx, err, "parsing flags")
+	}
+
+	if h.watch {
+		return h.watchInputs(ctx, args)
+	}
+
+	return h.count(ctx, args)
+}
+
+// resolveArgs produces every input to count, in the order they're
+// counted.
+func (h *handler) resolveArgs(ctx context.Context, args []string) ([]string, error) {
+	if len(h.manifest) > 0 {
+		listed, err := readManifest(h.manifest)
+		if err != nil {
+			return nil, cluerr.WrapWC(ctx, err, "reading inputs: "+h.manifest)
+		}
+
+		args = append(slices.Clone(args), listed...)
+	}
+
+	if len(args) == 0 {
+		args = []string{stdinPath}
+	}
+
+	args, err := h.inputs.resolveInputs(args)
+	if err != nil {
+		return nil, cluerr.WrapWC(ctx, err, "checking inputs")
+	}
+
+	if err := sortFiles(args, h.order); err != nil {
+		return nil, cluerr.WrapWC(ctx, err, "ordering files")
+	}
+
+	return args, nil
+}
+
+// count reads every input, then reports on the results.
+func (h *handler) count(ctx context.Context, args []string) error {
+	args, err := h.resolveArgs(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	if len(h.reportWordsFile) > 0 {
+		words, err := readWordList(h.reportWordsFile)
+		if err != nil {
+			return cluerr.WrapWC(ctx, err, "loading report words: "+h.reportWordsFile)
+		}
+
+		h.reportWords = words
+	}
+
+	if len(h.loadStateFrom) > 0 {
+		if err := h.loadState(h.loadStateFrom); err != nil {
+			return cluerr.WrapWC(ctx, err, "loading state: "+h.loadStateFrom)
+		}
+	}
+
+	if len(h.emitPath) > 0 {
+		f, err := os.Create(h.emitPath)
+		if err != nil {
+			return cluerr.WrapWC(ctx, err, "creating emit-normalized file: "+h.emitPath)
+		}
+
+		defer f.Close()
+
+		h.emitter = bufio.NewWriter(f)
+	}
+
+	// interleaved file tables are part of the report, so they go
+	// wherever the final report goes.
+	var out *os.File
+
+	if len(h.output) > 0 && h.interleave {
+		out, err = h.createOutput()
+		if err != nil {
+			return cluerr.WrapWC(ctx, err, "writing results: "+h.output)
+		}
+
+		defer os.Remove(out.Name())
+		defer out.Close()
+
+		h.reportTo = out
+	}
+
+	if h.maxRuntime > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, h.maxRuntime)
+		defer cancel()
+	}
+
+	// aggregate all stats per file
+	for _, arg := range args {
+		err := h.runFile(ctx, arg)
+		if errors.Is(err, context.DeadlineExceeded) {
+			clog.Ctx(ctx).
+				With("max_runtime", h.maxRuntime, "file", arg).
+				Info("max runtime exceeded; printing partial results")
+
+			break
+		}
+
+		if err != nil {
+			return cluerr.Wrap(err, "executing command")
+		}
+	}
+
+	if h.normalizePerFile {
+		h.mergeNormalized()
+	}
+
+	if h.emitter != nil {
+		if err := h.emitter.Flush(); err != nil {
+			return cluerr.WrapWC(ctx, err, "writing emit-normalized file: "+h.emitPath)
+		}
+	}
+
+	if len(h.saveStateTo) > 0 {
+		if err := h.saveState(h.saveStateTo); err != nil {
+			return cluerr.WrapWC(ctx, err, "saving state: "+h.saveStateTo)
+		}
+	}
+
+	if len(h.saveBaseline) > 0 {
+		if err := h.writeBaseline(h.saveBaseline); err != nil {
+			return cluerr.WrapWC(ctx, err, "saving baseline: "+h.saveBaseline)
+		}
+	}
+
+	if len(h.trigramPath) > 0 {
+		if err := h.writeTrigramProfile(h.trigramPath); err != nil {
+			return cluerr.WrapWC(ctx, err, "writing trigram profile: "+h.trigramPath)
+		}
+	}
+
+	switch {
+	case len(h.output) == 0:
+		if err := h.report(os.Stdout); err != nil {
+			return cluerr.WrapWC(ctx, err, "reporting results")
+		}
+	case out != nil:
+		if err := h.finishOutput(out); err != nil {
+			return cluerr.WrapWC(ctx, err, "writing results: "+h.output)
+		}
+	default:
+		if err := h.writeOutput(); err != nil {
+			return cluerr.WrapWC(ctx, err, "writing results: "+h.output)
+		}
+	}
+
+	if len(h.baseline) > 0 {
+		err := h.checkBaseline(os.Stderr)
+		return cluerr.WrapWC(ctx, err, "comparing against baseline").OrNil()
+	}
+
+	return nil
+}
+
+// writeOutput replaces the contents of the output file with the
+// current results.  The results are written to a temp file which
+// then gets moved into place, so that readers of the output never
+// see a partially written report.
+func (h *handler) writeOutput() error {
+	tmp, err := h.createOutput()
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	return h.finishOutput(tmp)
+}
+
+// createOutput creates the temp file that results get written to,
+// beside the output file.
+func (h *handler) createOutput() (*os.File, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(h.output), filepath.Base(h.output)+".*.tmp")
+	if err != nil {
+		return nil, cluerr.Wrap(err, "creating temp output file")
+	}
+
+	return tmp, nil
+}
+
+// finishOutput appends the current results to the temp file, then
+// moves it into place as the output file.
+func (h *handler) finishOutput(tmp *os.File) error {
+	if err := h.report(tmp); err != nil {
+		tmp.Close()
+		return cluerr.Wrap(err, "reporting results")
+	}
+
+	if err := tmp.Close(); err != nil {
+		return cluerr.Wrap(err, "closing temp output file")
+	}
+
+	return cluerr.Wrap(os.Rename(tmp.Name(), h.output), "replacing output file").OrNil()
+}
+
+// wordsView is the display configuration for word stats
+// showing up to top words.
+func (h *handler) wordsView(top int) view {
+	return view{
+		top:           top,
+		sample:        h.sampleWords,
+		seed:          h.seed,
+		rankLabel:     h.rankLabel,
+		distinctFreqs: h.distinctFreqs,
+		changed:       h.changed,
+		hide:          h.hideWords,
+	}
+}
+
+// largestTopWords is the top-words size that includes
+// the most words.
+func (h *handler) largestTopWords() int {
+	if slices.Contains(h.topWords, 0) {
+		return 0
+	}
+
+	return slices.Max(h.topWords)
+}
+
+// lettersView is the display configuration for letter stats.
+func (h *handler) lettersView() view {
+	v := view{rankLabel: h.rankLabel}
+
+	if h.groupVowels {
+		v.group = isVowel
+	}
+
+	return v
+}
+
+// tableSection is a single titled table of the report, along with
+// any footer lines describing it.
+type tableSection struct {
+	// writes the title, followed by the table.
+	table func(w io.Writer)
+	// each writes a line following the table.
+	footers []func(w io.Writer)
+}
+
+func (sec tableSection) print(w io.Writer) {
+	sec.table(w)
+
+	for _, footer := range sec.footers {
+		footer(w)
+	}
+}
+
+// printTables writes all stats as human readable tables.
+func (h *handler) printTables(w io.Writer) {
+	for i, sec := range h.tableSections() {
+		if i > 0 {
+			writeLn(w, " ")
+		}
+
+		sec.print(w)
+	}
+}
+
+// tableSections produces every table of the report, in order.
+func (h *handler) tableSections() []tableSection {
+	secs := []tableSection{}
+
+	if h.reportsFiles() {
+		for _, fc := range h.files {
+			secs = append(secs, h.fileSections(fc)...)
+		}
+	}
+
+	secs = append(secs, h.wordSections()...)
+
+	// the footers describe all of the word tables, so they
+	// follow the last one.
+	words := &secs[len(secs)-1]
+
+	if h.sketch != nil {
+		words.footers = append(words.footers, func(w io.Writer) {
+			printApproximate(h.sketch, h.autoApproxAt, w)
+		})
+	}
+
+	if h.lengthStats {
+		words.footers = append(words.footers, func(w io.Writer) {
+			printLengthStats(h.words, w)
+		})
+	}
+
+	if h.commonWord {
+		words.footers = append(words.footers, func(w io.Writer) {
+			printLongestCommonWord(h.files, w)
+		})
+	}
+
+	if h.percentiles {
+		secs = append(secs, tableSection{table: func(w io.Writer) {
+			printPercentiles(h.words, w)
+		}})
+	}
+
+	if len(h.reportWordsFile) > 0 {
+		secs = append(secs, tableSection{table: func(w io.Writer) {
+			printReportWords(h.words, h.reportWords, h.wordCount, w)
+		}})
+	}
+
+	if h.numbers == numbersSeparate {
+		secs = append(secs, tableSection{table: func(w io.Writer) {
+			print(h.numberStats, "numbers", view{rankLabel: h.rankLabel}, w)
+		}})
+	}
+
+	letters := tableSection{table: func(w io.Writer) {
+		print(h.letters, "letters", h.lettersView(), w)
+	}}
+
+	if h.onlySwapped {
+		letters.table = func(w io.Writer) {
+			printColumn(
+				"swapped letters",
+				"swapped",
+				h.rankLabel,
+				toUnitSlice(h.letters.swapped),
+				h.letters.countSwapped.Value(),
+				w,
+			)
+		}
+	}
+
+	if h.swapRatio {
+		letters.footers = append(letters.footers, func(w io.Writer) {
+			printSwapRatio(h.letters, w)
+		})
+	}
+
+	secs = append(secs, letters)
+
+	if h.scrabble {
+		secs = append(secs, tableSection{table: func(w io.Writer) {
+			printScrabble(h.words, h.letters, w)
+		}})
+	}
+
+	if h.letterRarity > 0 {
+		secs = append(secs, tableSection{table: func(w io.Writer) {
+			printLetterRarity(h.words, h.letters, h.letterRarity, h.rankLabel, w)
+		}})
+	}
+
+	if h.perLine {
+		secs = append(secs, tableSection{table: func(w io.Writer) {
+			printWordsPerLine(h.wordsPerLine, w)
+		}})
+	}
+
+	if h.blocks {
+		secs = append(secs, tableSection{table: func(w io.Writer) {
+			printBlocks(h.letters, w)
+		}})
+	}
+
+	if h.countGeminates {
+		secs = append(secs, tableSection{table: func(w io.Writer) {
+			print(h.geminates, "geminates", view{rankLabel: h.rankLabel}, w)
+		}})
+	}
+
+	if h.countOnsets {
+		secs = append(secs, tableSection{table: func(w io.Writer) {
+			print(h.onsets, "initial bigrams", view{rankLabel: h.rankLabel}, w)
+		}})
+	}
+
+	if h.uniqueGrowth {
+		secs = append(secs, tableSection{table: func(w io.Writer) {
+			printUniqueGrowth(h.growth, w)
+		}})
+	}
+
+	return secs
+}
+
+// wordSections produces the word tables: one for each top words
+// size, or a single table when sampling or keeping top frequencies.
+func (h *handler) wordSections() []tableSection {
+	switch {
+	case h.sampleWords > 0:
+		return []tableSection{{table: func(w io.Writer) {
+			print(h.words, "words (sample)", h.wordsView(0), w)
+		}}}
+	case h.distinctFreqs > 0:
+		title := fmt.Sprintf("words (top %d frequencies)", h.distinctFreqs)
+
+		return []tableSection{{table: func(w io.Writer) {
+			print(h.words, title, h.wordsView(0), w)
+		}}}
+	case len(h.topWords) == 1:
+		return []tableSection{{table: func(w io.Writer) {
+			print(h.words, "words", h.wordsView(h.topWords[0]), w)
+		}}}
+	}
+
+	secs := make([]tableSection, 0, len(h.topWords))
+
+	for _, top := range h.topWords {
+		title := fmt.Sprintf("words (top %d)", top)
+		if top == 0 {
+			title = "words (all)"
+		}
+
+		secs = append(secs, tableSection{table: func(w io.Writer) {
+			print(h.words, title, h.wordsView(top), w)
+		}})
+	}
+
+	return secs
+}
+
+func (h *handler) runFile(
+	ctx context.Context,
 	filePath string,
 ) error {
-	f, err := os.Open(filePath)
+	if isArchive(filePath) {
+		return h.runArchive(ctx, filePath)
+	}
+
+	if isDocument(filePath) {
+		return h.runDocument(ctx, filePath)
+	}
+
+	f, err := openInput(ctx, filePath)
 	if err != nil {
-		return cluerr.WrapWC(ctx, err, "opening file: "+filePath)
+		return cluerr.WrapWC(ctx, err, "opening input: "+filePath)
 	}
 
 	defer f.Close()
 
-	err = h.processFile(ctx, f)
+	return h.runReader(ctx, filePath, f)
+}
+
+// runReader counts the text read from r as a single corpus, named
+// by filePath.
+func (h *handler) runReader(
+	ctx context.Context,
+	filePath string,
+	rd io.Reader,
+) error {
+	h.startFile(filePath)
+	defer h.finishFile(h.reportTo)
+
+	br := bufio.NewReaderSize(rd, sniffSize)
+
+	isText, err := h.sniffText(ctx, filePath, br)
+	if err != nil || !isText {
+		return err
+	}
+
+	r, enc, err := decodeReader(br)
+	if err != nil {
+		return cluerr.WrapWC(ctx, err, "detecting encoding: "+filePath)
+	}
+
+	clog.Ctx(ctx).
+		With("file", filePath, "encoding", enc).
+		Debug("decoding file")
+
+	if delim, ok := tableDelimiter(filePath); ok && h.tableColumn != nil {
+		column := tableText(r, delim, *h.tableColumn)
+		defer column.Close()
+
+		r = column
+	}
+
+	if len(h.jsonlField) > 0 && isJSONL(filePath) {
+		field := jsonlText(r, h.jsonlField)
+		defer field.Close()
+
+		r = field
+	}
+
+	if h.removeHTML {
+		text := htmlText(r)
+		defer text.Close()
+
+		r = text
+	}
+
+	err = h.processFile(ctx, r, filePath)
 
 	return cluerr.WrapWC(
 		ctx,
@@ -234,7 +2156,8 @@ func (h *handler) runFile(
 
 func (h *handler) processFile(
 	ctx context.Context,
-	f *os.File,
+	r io.Reader,
+	filePath string,
 ) (err error) {
 	defer func() {
 		r := recover()
@@ -244,103 +2167,395 @@ func (h *handler) processFile(
 		}
 	}()
 
-	scanner := bufio.NewScanner(f)
+	scanner := bufio.NewScanner(r)
 	scanner.Split(bufio.ScanLines)
 
 	// prev and current represent lines of text scanned
 	// in by bufio.  We hold both lines in order to mediate
-	// words split in printing via -.
-	var prev, curr []string
-	var prevBroken, currBroken bool
+	// words split in printing via -.  Prev isn't counted
+	// until curr has had the chance to complete its last word.
+	var (
+		prev, curr scannedLine
+		// false until the first line gets scanned into prev.
+		scanned bool
+		// where the current line falls within a gutenberg text.
+		gutenberg gutenbergState
+		// whether the current line is within a markdown code fence.
+		fenced bool
+		// where the current line falls within a subtitle cue.
+		subtitles = isSubtitle(filePath)
+		subtitle  subtitleState
+		// where the current line falls within an mbox message.
+		mbox    = isMbox(filePath)
+		message = newMboxState(h.skipQuoted)
+		// whether the current line is within latex math.
+		latex latexState
+		// the 1-based number of the current line within the file.
+		lineNo int
+	)
 
 	for scanner.Scan() {
-		if len(prev) > 0 {
-			// assume we need to stitch together a broken word
-			if prevBroken && len(curr) > 0 {
-				prev[len(prev)-1] = prev[len(prev)-1] + curr[0]
-				curr = curr[1:]
+		// stop early when the context is cancelled, or its deadline
+		// passes, and keep whatever has been counted so far.
+		if ctx.Err() != nil {
+			break
+		}
+
+		var (
+			ln       = scanner.Text()
+			stripped int
+		)
+
+		lineNo++
+
+		// nothing past the end of the range gets counted.
+		if h.lines.past(lineNo) {
+			break
+		}
+
+		// filtered lines are still normalized so that words broken
+		// across a filtered and unfiltered line get stitched together,
+		// but none of the filtered line's own words are counted.
+		curr.counted = h.lineFilter == nil || h.lineFilter.MatchString(ln)
+
+		if !h.lines.contains(lineNo) {
+			curr.counted = false
+		}
+
+		if h.sampler != nil && h.sampler.Float64() >= h.sampleRate {
+			curr.counted = false
+		}
+
+		if h.stripGutenberg && !gutenberg.next(ln) {
+			curr.counted = false
+		}
+
+		if subtitles {
+			var isCue bool
+
+			if ln, isCue = subtitle.next(ln); !isCue {
+				curr.counted = false
+			}
+		}
+
+		if mbox {
+			var isBody bool
+
+			if ln, isBody = message.next(ln); !isBody {
+				curr.counted = false
+			}
+		}
+
+		if h.norm.latex && !latex.next(ln) {
+			curr.counted = false
+		}
+
+		if h.norm.markdown {
+			// the fence lines themselves are part of the code block.
+			if markdownFenceRE.MatchString(ln) {
+				fenced = !fenced
+				curr.counted = false
+			} else if fenced {
+				curr.counted = false
 			}
+		}
+
+		// the marker line itself still comes before the marker.
+		curr.pastMarker = h.pastMarker
+
+		if h.vocabAfter != nil && !h.pastMarker && h.vocabAfter.MatchString(ln) {
+			h.pastMarker = true
+		}
 
-			h.processLine(ctx, prev)
+		if h.matchRegion != nil {
+			ln = matchedRegion(h.matchRegion, ln)
+		}
+
+		curr.words, curr.broken, stripped = h.tokenizer.tokenize(ln)
+
+		if h.countStripped && curr.counted {
+			h.incStripped(stripped)
+		}
+
+		// assume we need to stitch together a broken word
+		if prev.broken && len(prev.words) > 0 && len(curr.words) > 0 {
+			prev.words[len(prev.words)-1] = prev.words[len(prev.words)-1] + curr.words[0]
+			curr.words = curr.words[1:]
+		}
+
+		if scanned {
+			h.processScanned(ctx, prev)
 		}
 
 		prev = curr
-		prevBroken = currBroken
-		curr, currBroken = normalize(scanner.Text(), h.removeHTML)
+		scanned = true
+
+		h.linesSeen++
+
+		if h.flushEvery > 0 && h.linesSeen%h.flushEvery == 0 {
+			if err := h.writeOutput(); err != nil {
+				return cluerr.WrapWC(ctx, err, "flushing results").
+					With("lines_seen", h.linesSeen)
+			}
+		}
 	}
 
 	// and one last call to catch the final line
-	h.processLine(ctx, curr)
+	if scanned {
+		h.processScanned(ctx, prev)
+	}
 
-	return nil
+	if h.stripGutenberg && gutenberg == gutenbergHeader {
+		clog.Ctx(ctx).Info("no project gutenberg start marker found; nothing was counted")
+	}
+
+	// includes failures while extracting text (ex: a missing csv
+	// column), which end the scan early.
+	if err := scanner.Err(); err != nil {
+		return cluerr.WrapWC(ctx, err, "scanning input")
+	}
+
+	return ctx.Err()
 }
 
-var (
-	keepCharsRE          = regexp.MustCompile(`[^a-zA-Z0-9 ]+`)
-	keepCharsAndAnglesRE = regexp.MustCompile(`[^a-zA-Z0-9 <>]+`)
-	removeHTMLRE         = regexp.MustCompile(` ?</?[a-zA-Z0-9]+> ?`)
-)
+// scannedLine is a single line of normalized text.
+type scannedLine struct {
+	words []string
+	// whether the original text ended in a dash-broken word.
+	broken bool
+	// whether the words in the line should get counted.
+	counted bool
+	// whether the line comes after the vocab-after marker.
+	pastMarker bool
+}
 
-// lowers and strips most non-alpha-numeric characters.
-func normalize(
-	ln string,
-	removeHTML bool,
-) (
-	[]string, // the revised text
-	bool, // whether the original text ended in a dash-broken word.
+// processScanned counts the words in the line, if they should be counted.
+func (h *handler) processScanned(
+	ctx context.Context,
+	ln scannedLine,
 ) {
-	// first to ensure we catch broken words.
-	ln = strings.TrimSpace(ln)
+	if h.emitter != nil {
+		// uncounted lines are emitted empty, to keep the emitted
+		// lines aligned with the source lines.
+		var emit string
+		if ln.counted {
+			emit = strings.Join(ln.words, " ")
+		}
+
+		writeLn(h.emitter, emit)
+	}
+
+	if !ln.counted {
+		return
+	}
+
+	if h.vocabAfter == nil {
+		h.processLine(ctx, ln.words)
+		return
+	}
+
+	if !ln.pastMarker {
+		for _, word := range ln.words {
+			h.vocabBefore[word] = struct{}{}
+		}
+
+		return
+	}
+
+	h.processLine(ctx, slices.DeleteFunc(slices.Clone(ln.words), func(word string) bool {
+		_, seen := h.vocabBefore[word]
+		return seen
+	}))
+}
+
+// matchedRegion reduces the line to only the text matched by re.
+// If re contains capture groups, only the captured text is kept.
+// Each match, or capture, is joined by a space.  Lines that don't
+// match are reduced to nothing.
+func matchedRegion(re *regexp.Regexp, ln string) string {
+	var regions []string
+
+	for _, match := range re.FindAllStringSubmatch(ln, -1) {
+		if len(match) == 1 {
+			regions = append(regions, match[0])
+			continue
+		}
 
-	if len(ln) == 0 {
-		return nil, false
+		regions = append(regions, match[1:]...)
 	}
 
-	broken := len(ln) > 1 &&
-		strings.HasSuffix(ln, "-") &&
-		string(ln[len(ln)-2]) != ""
+	return strings.Join(regions, " ")
+}
+
+// strippedLetter is the pseudo-letter that collects all characters
+// dropped by normalization when -countStripped is set.
+const strippedLetter = "(stripped)"
+
+// incStripped counts n stripped characters into the letter stats.
+// Stripped characters are never part of a word, and so can't be
+// removed, but they are counted in both the raw and swapped sets
+// so that every column accounts for the full input.
+func (h *handler) incStripped(n int) {
+	for range n {
+		h.incLetter(strippedLetter, strippedLetter, false)
+	}
+}
 
-	ln = strings.ToLower(ln)
-	ln = strings.TrimSpace(ln)
+// addRemoveWords adds the listed words to the removed words.  Listed
+// words are normalized the same as the corpus, so that (ex:) they
+// match with or without apostrophes kept.
+func (h *handler) addRemoveWords(listed []string) {
+	for _, entry := range listed {
+		words, _, _ := h.tokenizer.tokenize(entry)
+
+		for _, word := range words {
+			h.removeWords[strings.ToLower(word)] = struct{}{}
+		}
+	}
+}
 
-	if removeHTML {
-		// prereduction makes it easier to isolate html elements
-		ln = keepCharsAndAnglesRE.ReplaceAllString(ln, "")
-		ln = removeHTMLRE.ReplaceAllString(ln, "")
+// filterLengths removes the words that fall outside of the min and
+// max word lengths.  Lengths are counted in letters, the same as the
+// letters tables.
+func (h *handler) filterLengths(ln []string) []string {
+	if h.minWordLen == 0 && h.maxWordLen == 0 {
+		return ln
 	}
 
-	ln = keepCharsRE.ReplaceAllString(ln, "")
+	return slices.DeleteFunc(ln, func(word string) bool {
+		n := uniseg.GraphemeClusterCount(word)
+		return n < h.minWordLen || (h.maxWordLen > 0 && n > h.maxWordLen)
+	})
+}
 
-	return strings.Fields(ln), broken
+// countsLetter is true if the character gets counted in the letters
+// tables.  Apostrophes and hyphens kept within words aren't letters.
+// Characters are denied by the class of their base rune.
+func (h *handler) countsLetter(char string) bool {
+	r, _ := utf8.DecodeRuneInString(char)
+	return char != "'" && char != "-" && !unicode.IsOneOf(h.denyClasses, r)
 }
 
 func (h *handler) processLine(
 	ctx context.Context,
 	ln []string,
 ) {
+	ln = h.divertNumbers(ln)
+	ln = h.filterLengths(ln)
+
+	if h.perLine && len(ln) > 0 {
+		v, _ := h.wordsPerLine.LoadOrCompute(len(ln), func() (*xsync.Counter, bool) {
+			return xsync.NewCounter(), false
+		})
+
+		v.Inc()
+	}
+
 	for _, word := range ln {
 		// swapped characters
 		swapped := word
+		changed := 0
 
+		// each swap rewrites the output of the one before it.
 		for _, swap := range h.swapNGrams {
-			swapped = strings.ReplaceAll(word, swap.from, swap.to)
+			var n int
+
+			swapped, n = swap.apply(swapped)
+			changed += n
 		}
 
-		_, remove := h.removeWords[word]
+		if changed > 0 {
+			// later swaps can rewrite the output of earlier ones, but
+			// no more letters can change than the raw word holds.
+			h.letters.countChanged.Add(int64(min(changed, uniseg.GraphemeClusterCount(word))))
+		}
+
+		match := word
+		if h.removeAfterSwap {
+			match = swapped
+		}
+
+		if h.norm.caseSensitive {
+			// removed words are always lowercase.
+			match = strings.ToLower(match)
+		}
+
+		_, remove := h.removeWords[match]
+		remove = remove || slices.ContainsFunc(h.removeRegexes, func(re *regexp.Regexp) bool {
+			return re.MatchString(match)
+		})
+
+		if h.changed != nil && swapped != word {
+			h.changed.raw[word] = struct{}{}
+			h.changed.swapped[swapped] = struct{}{}
+		}
 
 		// count all words
-		inc(&h.words, word, swapped, remove)
+		h.incWord(ctx, word, swapped, remove)
 
 		// count all characters in the raw word
-		for _, char := range word {
-			inc(&h.letters, string(char), "", remove)
+		for _, char := range graphemes(word) {
+			if h.countsLetter(char) {
+				h.incLetter(char, "", remove)
+			}
 		}
 
 		// count all characters in the swapped wordset
-		for _, char := range swapped {
-			inc(&h.letters, "", string(char), remove)
+		for _, char := range graphemes(swapped) {
+			if h.countsLetter(char) {
+				h.incLetter("", char, remove)
+			}
+		}
+
+		if len(h.trigramPath) > 0 {
+			incTrigrams(h.trigrams, h.trigramCount, word)
+		}
+
+		if h.countGeminates {
+			for _, g := range geminatesOf(word) {
+				inc(&h.geminates, g, "", remove)
+			}
+
+			for _, g := range geminatesOf(swapped) {
+				inc(&h.geminates, "", g, remove)
+			}
+		}
+
+		if h.countOnsets {
+			inc(&h.onsets, onsetOf(word), "", remove)
+			inc(&h.onsets, "", onsetOf(swapped), remove)
+		}
+	}
+}
+
+// geminatesOf produces every doubled letter within the word.  Runs
+// longer than two letters produce one geminate per adjacent pair.
+func geminatesOf(word string) []string {
+	var (
+		gems []string
+		prev string
+	)
+
+	for i, char := range graphemes(word) {
+		if i > 0 && char == prev {
+			gems = append(gems, char+char)
 		}
+
+		prev = char
+	}
+
+	return gems
+}
+
+// onsetOf produces the first two letters of the word.  Words
+// shorter than two letters have no onset.
+func onsetOf(word string) string {
+	chars := graphemes(word)
+	if len(chars) < 2 {
+		return ""
 	}
+
+	return chars[0] + chars[1]
 }
 
 // inc mutates the stats maps to increment all values
@@ -400,43 +2615,114 @@ type unit struct {
 	n int
 }
 
+// view describes which units of each stat column get displayed.
+type view struct {
+	// the number of highest-count units to show.  0 shows all units.
+	top int
+	// when > 0, shows a random sample of this many units in place
+	// of the top units.
+	sample int
+	// the seed for sampling. The same seed over the same stats will
+	// always produce the same sample.
+	seed int64
+	// when non-nil, units for which group is true are printed
+	// before, and separately from, all other units.
+	group func(string) bool
+	// the header of the rank column.
+	rankLabel string
+	// when > 0, shows every unit whose count is within the highest
+	// distinctFreqs distinct counts, in place of the top units.
+	// Unlike top, this never cuts off units that tie in count.
+	distinctFreqs int
+	// when non-nil, only units changed by a swap are displayed.
+	changed *changedWords
+	// when non-nil, units matching the regex are never displayed.
+	// Hidden units still count towards all totals.
+	hide *regexp.Regexp
+}
+
+// changedWords tracks the words that were changed by swaps, both
+// in their raw form and in the swapped form they were changed into.
+type changedWords struct {
+	raw, swapped map[string]struct{}
+}
+
+// onlyChanged reduces the units to those changed by swaps.  When
+// swapped is true, the units are matched against the swapped forms.
+func (v view) onlyChanged(units []unit, swapped bool) []unit {
+	if v.changed == nil {
+		return units
+	}
+
+	set := v.changed.raw
+	if swapped {
+		set = v.changed.swapped
+	}
+
+	return slices.DeleteFunc(units, func(u unit) bool {
+		_, ok := set[u.v]
+		return !ok
+	})
+}
+
+// apply reduces the sorted units to only those that should be displayed.
+func (v view) apply(units []unit) []unit {
+	if v.hide != nil {
+		units = slices.DeleteFunc(units, func(u unit) bool {
+			return v.hide.MatchString(u.v)
+		})
+	}
+
+	if v.sample > 0 {
+		return sampleUnits(units, v.sample, v.seed)
+	}
+
+	if v.distinctFreqs > 0 {
+		return topDistinctFreqs(units, v.distinctFreqs)
+	}
+
+	if v.top > 0 && len(units) > v.top {
+		return units[:v.top]
+	}
+
+	return units
+}
+
 func print(
 	stats stats,
 	title string,
-	top int,
+	v view,
 	w io.Writer,
 ) {
 	var (
-		u = toUnitSlice(stats.universal)
-		r = toUnitSlice(stats.removed)
-		s = toUnitSlice(stats.swapped)
-		b = toUnitSlice(stats.both)
+		u = v.apply(v.onlyChanged(toUnitSlice(stats.universal), false))
+		r = v.apply(v.onlyChanged(toUnitSlice(stats.removed), false))
+		s = v.apply(v.onlyChanged(toUnitSlice(stats.swapped), true))
+		b = v.apply(v.onlyChanged(toUnitSlice(stats.both), true))
 	)
 
-	if top > 0 {
-		if len(u) > top {
-			u = u[:top]
-		}
-
-		if len(r) > top {
-			r = r[:top]
-		}
+	// each group is a set of rows printed together.  Without
+	// a grouping, all units belong to a single group.
+	groups := [][4][]unit{{u, r, s, b}}
 
-		if len(s) > top {
-			s = s[:top]
-		}
+	if v.group != nil {
+		groups = [][4][]unit{{}, {}}
 
-		if len(b) > top {
-			b = b[:top]
+		for col, units := range [4][]unit{u, r, s, b} {
+			for _, unit := range units {
+				if v.group(unit.v) {
+					groups[0][col] = append(groups[0][col], unit)
+				} else {
+					groups[1][col] = append(groups[1][col], unit)
+				}
+			}
 		}
 	}
 
-	longest := max(len(u), len(s), len(r), len(b))
-
 	writeLn(w, title)
 	writeLn(
 		w,
-		"|  "+
+		addRankHeader(v.rankLabel)+
 			addCellHeader("raw", stats.count.Value())+
 			addCellHeader("removed", stats.count.Value()-stats.countRemoved.Value())+
 			addCellHeader("swapped", stats.countSwapped.Value())+
@@ -445,19 +2731,218 @@ func print(
 	)
 	writeLn(w, "|---|---|---|---|---|")
 
-	for i := range longest {
+	for gi, g := range groups {
+		if gi > 0 {
+			writeLn(w, "|  |  |  |  |  |")
+		}
+
+		longest := max(len(g[0]), len(g[1]), len(g[2]), len(g[3]))
+
+		for i := range longest {
+			writeLn(
+				w,
+				fmt.Sprintf("| %2d ", i)+
+					addCellUnit(i, g[0], stats.count.Value())+
+					addCellUnit(i, g[1], stats.count.Value()-stats.countRemoved.Value())+
+					addCellUnit(i, g[2], stats.countSwapped.Value())+
+					addCellUnit(i, g[3], stats.countBoth.Value())+
+					"|",
+			)
+		}
+	}
+}
+
+// isVowel is true for the letters a, e, i, o, and u, in either case.
+func isVowel(letter string) bool {
+	return len(letter) == 1 && strings.Contains("aeiou", strings.ToLower(letter))
+}
+
+// printColumn writes a table containing only a single column of units.
+func printColumn(
+	title, column, rankLabel string,
+	units []unit,
+	total int64,
+	w io.Writer,
+) {
+	writeLn(w, title)
+	writeLn(w, addRankHeader(rankLabel)+addCellHeader(column, total)+"|")
+	writeLn(w, "|---|---|")
+
+	for i := range units {
 		writeLn(
 			w,
 			fmt.Sprintf("| %2d ", i)+
-				addCellUnit(i, u, stats.count.Value())+
-				addCellUnit(i, r, stats.count.Value()-stats.countRemoved.Value())+
-				addCellUnit(i, s, stats.countSwapped.Value())+
-				addCellUnit(i, b, stats.countBoth.Value())+
+				addCellUnit(i, units, total)+
 				"|",
 		)
 	}
 }
 
+// printWordsPerLine writes the distribution of words per line,
+// ordered by the count of words.
+func printWordsPerLine(
+	dist *xsync.Map[int, *xsync.Counter],
+	w io.Writer,
+) {
+	var (
+		total int64
+		sizes []int
+	)
+
+	dist.Range(func(key int, value *xsync.Counter) bool {
+		sizes = append(sizes, key)
+		total += value.Value()
+
+		return true
+	})
+
+	slices.Sort(sizes)
+
+	writeLn(w, "words per line")
+	writeLn(w, "| words "+addCellHeader("lines", total)+"|")
+	writeLn(w, "|---|---|")
+
+	for _, size := range sizes {
+		v, _ := dist.Load(size)
+		n := v.Value()
+
+		writeLn(w, fmt.Sprintf(
+			"| %5d | %6s, %2.2f%% |",
+			size,
+			human(n),
+			percent(int(n), total),
+		))
+	}
+}
+
+// printSwapRatio writes a footer line with the percent of raw
+// letter occurrences that were changed by swaps.
+func printSwapRatio(
+	stats stats,
+	w io.Writer,
+) {
+	changed := stats.countChanged.Value()
+
+	writeLn(w, fmt.Sprintf(
+		"letters changed by swaps: %s of %s (%2.2f%%)",
+		human(changed),
+		human(stats.count.Value()),
+		percent(int(changed), stats.count.Value()),
+	))
+}
+
+// reportedPercentiles are the percentiles shown by printPercentiles.
+var reportedPercentiles = []float64{50, 90, 99}
+
+// printPercentiles writes, for each reported percentile of raw word
+// occurrences, the count of the word at which that percentile is
+// reached, and how many distinct words it takes to reach it.
+func printPercentiles(
+	stats stats,
+	w io.Writer,
+) {
+	var (
+		units = toUnitSlice(stats.universal)
+		total = stats.count.Value()
+	)
+
+	writeLn(w, "word percentiles")
+	writeLn(w, "| percentile | count | words |")
+	writeLn(w, "|---|---|---|")
+
+	for _, p := range reportedPercentiles {
+		count, words := percentileOf(units, total, p)
+
+		writeLn(w, fmt.Sprintf(
+			"| p%g | %s | %s |",
+			p,
+			human(count),
+			human(words),
+		))
+	}
+}
+
+// percentileOf walks the sorted units until their cumulative count
+// reaches p percent of the total.  Produces the count of the unit
+// that reached the percentile, and the number of units walked.
+func percentileOf(
+	units []unit,
+	total int64,
+	p float64,
+) (int, int) {
+	var cumulative int64
+
+	for i, u := range units {
+		cumulative += int64(u.n)
+
+		if float64(cumulative) >= float64(total)*p/100 {
+			return u.n, i + 1
+		}
+	}
+
+	return 0, len(units)
+}
+
+// printLengthStats writes a footer line with the mean and median
+// length, in runes, of all raw words.
+func printLengthStats(
+	stats stats,
+	w io.Writer,
+) {
+	mean, median := wordLengths(stats.universal)
+
+	writeLn(w, fmt.Sprintf("mean word length: %.2f, median word length: %.1f", mean, median))
+}
+
+// wordLengths produces the mean and median rune length of all
+// word occurrences in the counter.
+func wordLengths(counter *xsync.Map[string, *xsync.Counter]) (float64, float64) {
+	var (
+		total, sum int64
+		// distribution of word length -> occurrences
+		dist = map[int]int64{}
+	)
+
+	counter.Range(func(key string, value *xsync.Counter) bool {
+		n := value.Value()
+		l := utf8.RuneCountInString(key)
+		dist[l] += n
+		total += n
+		sum += int64(l) * n
+
+		return true
+	})
+
+	if total == 0 {
+		return 0, 0
+	}
+
+	lengths := slices.Sorted(maps.Keys(dist))
+
+	// nth finds the length of the nth (zero-indexed) occurrence
+	// in length-sorted order.
+	nth := func(i int64) int {
+		var seen int64
+
+		for _, l := range lengths {
+			seen += dist[l]
+			if i < seen {
+				return l
+			}
+		}
+
+		return lengths[len(lengths)-1]
+	}
+
+	median := float64(nth(total / 2))
+
+	if total%2 == 0 {
+		median = (float64(nth(total/2-1)) + median) / 2
+	}
+
+	return float64(sum) / float64(total), median
+}
+
 func toUnitSlice(counter *xsync.Map[string, *xsync.Counter]) []unit {
 	result := []unit{}
 
@@ -466,7 +2951,14 @@ func toUnitSlice(counter *xsync.Map[string, *xsync.Counter]) []unit {
 		return true
 	})
 
-	slices.SortFunc(result, func(a, b unit) int {
+	sortUnits(result)
+
+	return result
+}
+
+// sortUnits orders units by descending count, then alphabetically.
+func sortUnits(units []unit) {
+	slices.SortFunc(units, func(a, b unit) int {
 		diff := b.n - a.n
 		if diff != 0 {
 			return diff
@@ -474,8 +2966,53 @@ func toUnitSlice(counter *xsync.Map[string, *xsync.Counter]) []unit {
 
 		return strings.Compare(a.v, b.v)
 	})
+}
 
-	return result
+// topDistinctFreqs reduces the sorted units to those whose count
+// is among the k highest distinct counts.
+func topDistinctFreqs(units []unit, k int) []unit {
+	distinct := 0
+
+	for i, u := range units {
+		if i == 0 || u.n != units[i-1].n {
+			distinct++
+		}
+
+		if distinct > k {
+			return units[:i]
+		}
+	}
+
+	return units
+}
+
+// sampleUnits reservoir-samples n units from the sorted slice.  Since
+// the input is always sorted, the same seed produces the same sample.
+// The sample is returned in sorted order.
+func sampleUnits(
+	units []unit,
+	n int,
+	seed int64,
+) []unit {
+	if len(units) <= n {
+		return units
+	}
+
+	var (
+		rnd       = rand.New(rand.NewPCG(uint64(seed), 0))
+		reservoir = slices.Clone(units[:n])
+	)
+
+	for i := n; i < len(units); i++ {
+		j := rnd.IntN(i + 1)
+		if j < n {
+			reservoir[j] = units[i]
+		}
+	}
+
+	sortUnits(reservoir)
+
+	return reservoir
 }
 
 func writeLn(
@@ -485,6 +3022,11 @@ func writeLn(
 	fmt.Fprint(w, ln+"\n")
 }
 
+// addRankHeader produces the header cell of the rank column.
+func addRankHeader(label string) string {
+	return "| " + label + " "
+}
+
 func addCellHeader(
 	title string,
 	total int64,
@@ -515,7 +3057,12 @@ type inter interface {
 	int | int64
 }
 
+// siPrecision is the count of decimal places in humanized values.
+// Set once from --si-precision, since humanizing happens throughout
+// all of the reports.
+var siPrecision = 1
+
 func human[Z inter](z Z) string {
 	hzr, _ := humanize.New("en")
-	return hzr.SiPrefixFast(float64(z))
+	return hzr.SiPrefix(float64(z), siPrecision, 1000, true)
 }
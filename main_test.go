@@ -0,0 +1,1065 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/puzpuzpuz/xsync/v4"
+)
+
+// execCount runs the count command over args with a fresh handler.
+// Every flag is reset to its default when the command is built.
+func execCount(h *handler, args ...string) error {
+	root := newRoot(h)
+
+	root.SetArgs(args)
+	root.SetOut(io.Discard)
+	root.SetErr(io.Discard)
+
+	return root.ExecuteContext(context.Background())
+}
+
+// runSubcommand runs the subcommand of count described by args.
+// Produces everything the subcommand wrote to its output.
+func runSubcommand(t *testing.T, args ...string) string {
+	t.Helper()
+
+	var (
+		root = newRoot(newHandler())
+		out  bytes.Buffer
+	)
+
+	root.SetArgs(args)
+	root.SetOut(&out)
+	root.SetErr(io.Discard)
+
+	if err := root.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("count %v: %v", args, err)
+	}
+
+	return out.String()
+}
+
+// runCount runs the count command over args, with the report written
+// to a temp file.  Produces the handler, for inspecting its stats, and
+// the report.
+func runCount(t *testing.T, args ...string) (*handler, string) {
+	t.Helper()
+
+	var (
+		h   = newHandler()
+		out = filepath.Join(t.TempDir(), "report.txt")
+	)
+
+	if err := execCount(h, append([]string{"-o", out}, args...)...); err != nil {
+		t.Fatalf("count %v: %v", args, err)
+	}
+
+	report, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("reading report: %v", err)
+	}
+
+	return h, string(report)
+}
+
+// writeInput writes the text to a file named name within dir.
+// Produces the path of the file.
+func writeInput(t *testing.T, dir, name, text string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatalf("writing input: %v", err)
+	}
+
+	return path
+}
+
+// countOf is the count of k, or 0 if k was never counted.
+func countOf(m *xsync.Map[string, *xsync.Counter], k string) int64 {
+	v, ok := m.Load(k)
+	if !ok {
+		return 0
+	}
+
+	return v.Value()
+}
+
+// tableRows produces the value of the raw column of every row in the
+// table titled title.  Blank rows, which separate groups, are skipped.
+func tableRows(t *testing.T, report, title string) []string {
+	t.Helper()
+
+	lines := strings.Split(report, "\n")
+
+	start := slices.Index(lines, title)
+	if start < 0 {
+		t.Fatalf("no table titled %q in report:\n%s", title, report)
+	}
+
+	var rows []string
+
+	// skips the title, header, and separator lines.
+	for _, ln := range lines[min(start+3, len(lines)):] {
+		if !strings.HasPrefix(ln, "|") {
+			break
+		}
+
+		cells := strings.Split(ln, "|")
+
+		v, _, _ := strings.Cut(strings.TrimSpace(cells[2]), " (")
+		if len(v) > 0 {
+			rows = append(rows, v)
+		}
+	}
+
+	return rows
+}
+
+func TestCountStripped(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "Hello, world!\n")
+
+	h, _ := runCount(t, "--countStripped", input)
+
+	if got := countOf(h.letters.universal, strippedLetter); got != 2 {
+		t.Errorf("stripped count = %d, want 2", got)
+	}
+
+	// every non-space character of the input is accounted for.
+	if got := h.letters.count.Value(); got != 12 {
+		t.Errorf("letter count = %d, want 12", got)
+	}
+}
+
+func TestCountStrippedUnset(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "Hello, world!\n")
+
+	h, _ := runCount(t, input)
+
+	if got := countOf(h.letters.universal, strippedLetter); got != 0 {
+		t.Errorf("stripped count = %d, want 0", got)
+	}
+}
+
+func TestProcessFileCountsEveryLine(t *testing.T) {
+	h := newHandler()
+
+	err := h.processFile(context.Background(), strings.NewReader("one\ntwo\nthree\n"), "in.txt")
+	if err != nil {
+		t.Fatalf("processing file: %v", err)
+	}
+
+	if got := h.words.count.Value(); got != 3 {
+		t.Errorf("word count = %d, want 3", got)
+	}
+
+	for _, word := range []string{"one", "two", "three"} {
+		if got := countOf(h.words.universal, word); got != 1 {
+			t.Errorf("count of %q = %d, want 1", word, got)
+		}
+	}
+}
+
+func TestSampleWordsReproducible(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "a b c d e f g h i j k l m n o p\n")
+
+	_, first := runCount(t, "--sample-words=4", "--seed=42", input)
+	_, second := runCount(t, "--sample-words=4", "--seed=42", input)
+
+	if first != second {
+		t.Errorf("samples differ for the same seed:\n%s\n%s", first, second)
+	}
+
+	if rows := tableRows(t, first, "words (sample)"); len(rows) != 4 {
+		t.Errorf("sampled %d words, want 4: %v", len(rows), rows)
+	}
+}
+
+func TestSampleLines(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", strings.Repeat("line\n", 1000))
+
+	h, _ := runCount(t, "--sample=0.25", "--seed=42", input)
+
+	// a 1 in 4 sample of 1000 lines is all but certain to land within
+	// 150 of 250.
+	sampled := countOf(h.words.universal, "line")
+	if sampled < 100 || sampled > 400 {
+		t.Errorf("sampled %d of 1000 lines, want about 250", sampled)
+	}
+
+	h, _ = runCount(t, "--sample=0.25", "--seed=42", input)
+
+	if got := countOf(h.words.universal, "line"); got != sampled {
+		t.Errorf("sampled %d lines, then %d, for the same seed", sampled, got)
+	}
+
+	h, _ = runCount(t, "--sample=1", input)
+
+	if got := countOf(h.words.universal, "line"); got != 1000 {
+		t.Errorf("sampled %d lines at a rate of 1, want all 1000", got)
+	}
+
+	for _, rate := range []string{"-0.5", "1.5"} {
+		err := execCount(newHandler(), "--sample="+rate, input)
+		if err == nil || !strings.Contains(err.Error(), "sample must be between 0 and 1") {
+			t.Errorf("--sample=%s: expected a range error, got %v", rate, err)
+		}
+	}
+}
+
+func TestSampleUnits(t *testing.T) {
+	var units []unit
+
+	for i := range 100 {
+		units = append(units, unit{v: string(rune('a' + i%26)), n: 100 - i})
+	}
+
+	sample := sampleUnits(units, 10, 7)
+
+	if len(sample) != 10 {
+		t.Fatalf("sampled %d units, want 10", len(sample))
+	}
+
+	if !slices.Equal(sample, sampleUnits(units, 10, 7)) {
+		t.Error("samples differ for the same seed")
+	}
+
+	if !slices.IsSortedFunc(sample, func(a, b unit) int { return b.n - a.n }) {
+		t.Errorf("sample is not sorted: %v", sample)
+	}
+
+	if got := sampleUnits(units[:5], 10, 7); len(got) != 5 {
+		t.Errorf("sampled %d of 5 units, want all 5", len(got))
+	}
+}
+
+func TestLengthStats(t *testing.T) {
+	// lengths 1, 2, 3, 4, 4, and 10.
+	input := writeInput(t, t.TempDir(), "in.txt", "a bb ccc dddd dddd\nabcdefghij\n")
+
+	h, report := runCount(t, "--stats", input)
+
+	mean, median := wordLengths(h.words.universal)
+
+	if mean != 4 {
+		t.Errorf("mean = %v, want 4", mean)
+	}
+
+	if median != 3.5 {
+		t.Errorf("median = %v, want 3.5", median)
+	}
+
+	want := "mean word length: 4.00, median word length: 3.5"
+	if !strings.Contains(report, want) {
+		t.Errorf("report is missing %q:\n%s", want, report)
+	}
+}
+
+func TestWordLengthsOddCount(t *testing.T) {
+	h := newHandler()
+	h.processLine(context.Background(), []string{"a", "bb", "ccc", "dddd", "dddd"})
+
+	mean, median := wordLengths(h.words.universal)
+
+	if mean != 2.8 || median != 3 {
+		t.Errorf("mean, median = %v, %v, want 2.8, 3", mean, median)
+	}
+}
+
+func TestLettersOnlySwapped(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "the then\n")
+
+	_, report := runCount(t, "--letters-only-swapped", "-s=th,ð", input)
+
+	rows := tableRows(t, report, "swapped letters")
+	if want := []string{"e", "ð", "n"}; !slices.Equal(rows, want) {
+		t.Errorf("swapped letters = %v, want %v", rows, want)
+	}
+
+	if !strings.Contains(report, "| # | swapped (5) |\n") {
+		t.Errorf("swapped letters header is missing its total:\n%s", report)
+	}
+
+	if strings.Contains(report, "\nletters\n") {
+		t.Errorf("report still contains the full letters table:\n%s", report)
+	}
+}
+
+// slowReader produces an endless stream of lines, one line per read,
+// waiting delay before each.
+type slowReader struct {
+	line  string
+	delay time.Duration
+}
+
+func (r slowReader) Read(p []byte) (int, error) {
+	time.Sleep(r.delay)
+	return copy(p, r.line+"\n"), nil
+}
+
+func TestMaxRuntimePartialResults(t *testing.T) {
+	var (
+		h           = newHandler()
+		ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	)
+
+	defer cancel()
+
+	done := make(chan error)
+
+	go func() {
+		done <- h.processFile(ctx, slowReader{"word", 5 * time.Millisecond}, "in.txt")
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("processing didn't stop at the deadline")
+	}
+
+	if got := countOf(h.words.universal, "word"); got == 0 {
+		t.Error("no partial results were counted")
+	}
+}
+
+func TestMaxRuntimeNegative(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "word\n")
+
+	if err := execCount(newHandler(), "--max-runtime=-1s", input); err == nil {
+		t.Error("expected an error for a negative max-runtime")
+	}
+}
+
+func TestMatchRegion(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "id: 1 name: alice liddell\nid: 2 name: bob\nno match here\n")
+
+	h, _ := runCount(t, "--match-region=name: (.*)", input)
+
+	for _, word := range []string{"alice", "liddell", "bob"} {
+		if got := countOf(h.words.universal, word); got != 1 {
+			t.Errorf("count of %q = %d, want 1", word, got)
+		}
+	}
+
+	if got := h.words.count.Value(); got != 3 {
+		t.Errorf("word count = %d, want 3", got)
+	}
+}
+
+func TestMatchedRegion(t *testing.T) {
+	table := []struct {
+		name, re, ln, want string
+	}{
+		{"capture", `name: (\w+)`, "name: alice, name: bob", "alice bob"},
+		{"no capture", `[a-z]+`, "a1b2", "a b"},
+		{"no match", `name: (.*)`, "age: 30", ""},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			got := matchedRegion(regexp.MustCompile(test.re), test.ln)
+			if got != test.want {
+				t.Errorf("matched %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRequireSwaps(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "the cat\n")
+
+	for _, flag := range []string{
+		"--letters-only-swapped",
+		"--swap-ratio",
+		"--only-changed-by-swap",
+	} {
+		t.Run(flag, func(t *testing.T) {
+			err := execCount(newHandler(), flag, input)
+			if err == nil || !strings.Contains(err.Error(), "requires at least one swapNgram") {
+				t.Errorf("error = %v, want a missing swaps error", err)
+			}
+
+			runCount(t, flag, "-s=th,ð", input)
+		})
+	}
+}
+
+// snapshotReader produces one line per read.  Before every read, it
+// records the contents of the file at path, if they've changed.
+type snapshotReader struct {
+	lines     []string
+	path      string
+	snapshots []string
+}
+
+func (r *snapshotReader) Read(p []byte) (int, error) {
+	if bs, err := os.ReadFile(r.path); err == nil {
+		if n := len(r.snapshots); n == 0 || r.snapshots[n-1] != string(bs) {
+			r.snapshots = append(r.snapshots, string(bs))
+		}
+	}
+
+	if len(r.lines) == 0 {
+		return 0, io.EOF
+	}
+
+	n := copy(p, r.lines[0]+"\n")
+	r.lines = r.lines[1:]
+
+	return n, nil
+}
+
+func TestFlushEvery(t *testing.T) {
+	var (
+		h = newHandler()
+		r = &snapshotReader{
+			lines: []string{"alpha", "beta", "gamma", "delta", "epsilon"},
+			path:  filepath.Join(t.TempDir(), "out.txt"),
+		}
+	)
+
+	h.output = r.path
+	h.flushEvery = 2
+
+	if err := h.processFile(context.Background(), r, "in.txt"); err != nil {
+		t.Fatalf("processing file: %v", err)
+	}
+
+	if len(r.snapshots) != 2 {
+		t.Fatalf("saw %d versions of the output, want 2: %q", len(r.snapshots), r.snapshots)
+	}
+
+	// each line is counted once the next line is scanned, so each
+	// flush holds up to the line before it.
+	first, second := r.snapshots[0], r.snapshots[1]
+
+	if !strings.Contains(first, "alpha") || strings.Contains(first, "beta") {
+		t.Errorf("first flush should only contain alpha:\n%s", first)
+	}
+
+	if !strings.Contains(second, "gamma") || strings.Contains(second, "delta") {
+		t.Errorf("second flush should contain up to gamma:\n%s", second)
+	}
+}
+
+func TestFlushEveryRequiresOutput(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "word\n")
+
+	if err := execCount(newHandler(), "--flush-every=2", input); err == nil {
+		t.Error("expected an error for flush-every without an output file")
+	}
+}
+
+func TestTopWordsThresholds(t *testing.T) {
+	var words []string
+
+	for i := range 150 {
+		words = append(words, fmt.Sprintf("w%03d", i))
+	}
+
+	input := writeInput(t, t.TempDir(), "in.txt", strings.Join(words, " ")+"\n")
+
+	_, report := runCount(t, "--top-words=10,100", input)
+
+	if rows := tableRows(t, report, "words (top 10)"); len(rows) != 10 {
+		t.Errorf("top 10 table has %d rows", len(rows))
+	}
+
+	if rows := tableRows(t, report, "words (top 100)"); len(rows) != 100 {
+		t.Errorf("top 100 table has %d rows", len(rows))
+	}
+
+	_, report = runCount(t, "--top-words=5,0", input)
+
+	if rows := tableRows(t, report, "words (all)"); len(rows) != 150 {
+		t.Errorf("all words table has %d rows", len(rows))
+	}
+}
+
+func TestLettersGroupVowels(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "strength a\n")
+
+	_, report := runCount(t, "--letters-group-vowels", input)
+
+	rows := tableRows(t, report, "letters")
+	if want := []string{"a", "e", "t", "g", "h", "n", "r", "s"}; !slices.Equal(rows, want) {
+		t.Errorf("letters = %v, want %v", rows, want)
+	}
+
+	// the groups are separated by a blank row.
+	if !strings.Contains(report, "e (     1, 11.11%) |\n|  |  |  |  |  |\n|  0 |     t (") {
+		t.Errorf("vowels aren't separated from consonants:\n%s", report)
+	}
+
+	_, report = runCount(t, input)
+
+	rows = tableRows(t, report, "letters")
+	if want := []string{"t", "a", "e", "g", "h", "n", "r", "s"}; !slices.Equal(rows, want) {
+		t.Errorf("ungrouped letters = %v, want %v", rows, want)
+	}
+}
+
+func TestIsVowel(t *testing.T) {
+	for _, letter := range []string{"a", "E", "i", "O", "u"} {
+		if !isVowel(letter) {
+			t.Errorf("%q should be a vowel", letter)
+		}
+	}
+
+	for _, letter := range []string{"b", "Y", "é", "ae"} {
+		if isVowel(letter) {
+			t.Errorf("%q shouldn't be a vowel", letter)
+		}
+	}
+}
+
+func TestWordsPerLine(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "one two three\nfour\nfive six seven\n\neight\n!!!\n")
+
+	h, report := runCount(t, "--words-per-line", input)
+
+	// blank lines, and lines without any words, aren't counted.
+	want := map[int]int64{1: 2, 3: 2}
+	got := map[int]int64{}
+
+	h.wordsPerLine.Range(func(key int, value *xsync.Counter) bool {
+		got[key] = value.Value()
+		return true
+	})
+
+	if !maps.Equal(got, want) {
+		t.Errorf("words per line = %v, want %v", got, want)
+	}
+
+	for _, row := range []string{
+		"| words | lines (4) |",
+		"|     1 |      2, 50.00% |",
+		"|     3 |      2, 50.00% |",
+	} {
+		if !strings.Contains(report, row) {
+			t.Errorf("report is missing %q:\n%s", row, report)
+		}
+	}
+}
+
+func TestRankLabel(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "b a a\n")
+
+	_, report := runCount(t, "--rank-label=rank", input)
+
+	want := strings.Join([]string{
+		"words",
+		"| rank | raw (3) | removed (3) | swapped (3) | both (3) |",
+		"|---|---|---|---|---|",
+		"|  0 |     a (     2, 66.67%) |     a (     2, 66.67%) |     a (     2, 66.67%) |     a (     2, 66.67%) |",
+		"|  1 |     b (     1, 33.33%) |     b (     1, 33.33%) |     b (     1, 33.33%) |     b (     1, 33.33%) |",
+		" ",
+		"letters",
+		"| rank | raw (3) | removed (3) | swapped (3) | both (3) |",
+		"|---|---|---|---|---|",
+		"|  0 |     a (     2, 66.67%) |     a (     2, 66.67%) |     a (     2, 66.67%) |     a (     2, 66.67%) |",
+		"|  1 |     b (     1, 33.33%) |     b (     1, 33.33%) |     b (     1, 33.33%) |     b (     1, 33.33%) |",
+		"",
+	}, "\n")
+
+	if report != want {
+		t.Errorf("report:\n%s\nwant:\n%s", report, want)
+	}
+
+	_, report = runCount(t, input)
+
+	if !strings.HasPrefix(report, "words\n| # | raw (3) |") {
+		t.Errorf("report is missing the default rank label:\n%s", report)
+	}
+}
+
+func TestLineFilter(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "ALICE: hello there\n(she waves)\nBOB: hi alice\nexeunt\n")
+
+	h, _ := runCount(t, "--line-filter=^[A-Z]+:", input)
+
+	if got := h.words.count.Value(); got != 6 {
+		t.Errorf("word count = %d, want 6", got)
+	}
+
+	if got := countOf(h.words.universal, "alice"); got != 2 {
+		t.Errorf("count of alice = %d, want 2", got)
+	}
+
+	for _, word := range []string{"she", "waves", "exeunt"} {
+		if got := countOf(h.words.universal, word); got != 0 {
+			t.Errorf("count of unmatched %q = %d, want 0", word, got)
+		}
+	}
+}
+
+func TestEmitNormalized(t *testing.T) {
+	var (
+		dir   = t.TempDir()
+		emit  = filepath.Join(dir, "norm.txt")
+		input = writeInput(t, dir, "in.txt", "Hello,   World!\nDon't stop\n\nskip me\nThe end.\n")
+	)
+
+	runCount(t, "--emit-normalized="+emit, "--line-filter=^[^s]", input)
+
+	bs, err := os.ReadFile(emit)
+	if err != nil {
+		t.Fatalf("reading emitted file: %v", err)
+	}
+
+	// lines that aren't counted stay, empty, to keep the lines aligned.
+	want := "hello world\ndont stop\n\n\nthe end\n"
+	if string(bs) != want {
+		t.Errorf("emitted %q, want %q", string(bs), want)
+	}
+}
+
+func TestSwapRatio(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "the cat\n")
+
+	table := []struct {
+		name string
+		swap string
+		want string
+	}{
+		{"shrinking swap", "-s=th,ð", "letters changed by swaps: 2 of 6 (33.33%)"},
+		{"growing swap", "-s=e,ea", "letters changed by swaps: 1 of 6 (16.67%)"},
+		{"unmatched swap", "-s=zz,z", "letters changed by swaps: 0 of 6 (0.00%)"},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			_, report := runCount(t, "--swap-ratio", test.swap, input)
+
+			if !strings.Contains(report, test.want+"\n") {
+				t.Errorf("report is missing %q:\n%s", test.want, report)
+			}
+		})
+	}
+}
+
+func TestTopDistinctFreqs(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "a a a b b b c c d d e e f\n")
+
+	_, report := runCount(t, "--top-distinct-freqs=2", input)
+
+	// every word tied at the 2 highest counts is kept.
+	rows := tableRows(t, report, "words (top 2 frequencies)")
+	if want := []string{"a", "b", "c", "d", "e"}; !slices.Equal(rows, want) {
+		t.Errorf("words = %v, want %v", rows, want)
+	}
+
+	units := []unit{{"a", 3}, {"b", 3}, {"c", 2}}
+
+	if got := topDistinctFreqs(units, 1); len(got) != 2 {
+		t.Errorf("top 1 frequency = %v, want both words tied at 3", got)
+	}
+
+	if got := topDistinctFreqs(units, 5); len(got) != 3 {
+		t.Errorf("top 5 frequencies = %v, want all words", got)
+	}
+}
+
+func TestGeminatesOf(t *testing.T) {
+	table := []struct {
+		word string
+		want []string
+	}{
+		{"hello", []string{"ll"}},
+		{"see", []string{"ee"}},
+		{"bookkeeper", []string{"oo", "kk", "ee"}},
+		{"aaa", []string{"aa", "aa"}},
+		{"cat", nil},
+		// é is a single letter, whether composed or not.
+		{"éé", []string{"éé"}},
+	}
+
+	for _, test := range table {
+		if got := geminatesOf(test.word); !slices.Equal(got, test.want) {
+			t.Errorf("geminates of %q = %q, want %q", test.word, got, test.want)
+		}
+	}
+}
+
+func TestGeminates(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "hello see see\n")
+
+	h, report := runCount(t, "--geminates", input)
+
+	if got := countOf(h.geminates.universal, "ll"); got != 1 {
+		t.Errorf("count of ll = %d, want 1", got)
+	}
+
+	if got := countOf(h.geminates.universal, "ee"); got != 2 {
+		t.Errorf("count of ee = %d, want 2", got)
+	}
+
+	rows := tableRows(t, report, "geminates")
+	if want := []string{"ee", "ll"}; !slices.Equal(rows, want) {
+		t.Errorf("geminates = %v, want %v", rows, want)
+	}
+}
+
+func TestPercentiles(t *testing.T) {
+	units := []unit{{"a", 50}, {"b", 40}, {"c", 9}, {"d", 1}}
+
+	table := []struct {
+		p                    float64
+		wantCount, wantWords int
+	}{
+		{50, 50, 1},
+		{90, 40, 2},
+		{99, 9, 3},
+		{100, 1, 4},
+	}
+
+	for _, test := range table {
+		count, words := percentileOf(units, 100, test.p)
+		if count != test.wantCount || words != test.wantWords {
+			t.Errorf("p%g = %d, %d, want %d, %d", test.p, count, words, test.wantCount, test.wantWords)
+		}
+	}
+}
+
+func TestPercentilesReport(t *testing.T) {
+	words := strings.Repeat("a ", 50) + strings.Repeat("b ", 40) + strings.Repeat("c ", 9) + "d"
+	input := writeInput(t, t.TempDir(), "in.txt", words+"\n")
+
+	_, report := runCount(t, "--percentile", input)
+
+	for _, row := range []string{
+		"| p50 | 50 | 1 |",
+		"| p90 | 40 | 2 |",
+		"| p99 | 9 | 3 |",
+	} {
+		if !strings.Contains(report, row) {
+			t.Errorf("report is missing %q:\n%s", row, report)
+		}
+	}
+}
+
+func TestRemoveAfterSwap(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "the cat then\n")
+
+	h, _ := runCount(t, "--remove-after-swap", "-s=th,ð", "-r=ðe", input)
+
+	if got := h.words.countRemoved.Value(); got != 1 {
+		t.Errorf("removed word count = %d, want 1", got)
+	}
+
+	// the raw and swapped forms are both removed.
+	if got := countOf(h.words.removed, "the"); got != 0 {
+		t.Errorf("count of the in removed = %d, want 0", got)
+	}
+
+	if got := countOf(h.words.both, "ðe"); got != 0 {
+		t.Errorf("count of ðe in both = %d, want 0", got)
+	}
+
+	// while the swapped column still counts it.
+	if got := countOf(h.words.swapped, "ðe"); got != 1 {
+		t.Errorf("count of ðe in swapped = %d, want 1", got)
+	}
+
+	if got := countOf(h.words.both, "ðen"); got != 1 {
+		t.Errorf("count of ðen in both = %d, want 1", got)
+	}
+
+	if got := h.letters.countBoth.Value(); got != 6 {
+		t.Errorf("both letter count = %d, want 6", got)
+	}
+
+	h, _ = runCount(t, "-s=th,ð", "-r=ðe", input)
+
+	if got := h.words.countRemoved.Value(); got != 0 {
+		t.Errorf("removed word count before swapping = %d, want 0", got)
+	}
+}
+
+func TestVocabAfter(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "the cat sat\nCHAPTER TWO\nthe dog sat\nthe bird, two dogs\n")
+
+	h, _ := runCount(t, "--vocab-after=^CHAPTER", input)
+
+	want := map[string]int64{"dog": 1, "bird": 1, "dogs": 1}
+	got := map[string]int64{}
+
+	for _, u := range toUnitSlice(h.words.universal) {
+		got[u.v] = int64(u.n)
+	}
+
+	// words seen before the marker, or on the marker line itself,
+	// are never counted.
+	if !maps.Equal(got, want) {
+		t.Errorf("words = %v, want %v", got, want)
+	}
+}
+
+func TestOnlyChangedBySwap(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "the cat then the\n")
+
+	h, report := runCount(t, "--only-changed-by-swap", "-s=th,ð", input)
+
+	rows := tableRows(t, report, "words")
+	if want := []string{"the", "then"}; !slices.Equal(rows, want) {
+		t.Errorf("words = %v, want %v", rows, want)
+	}
+
+	if !strings.Contains(report, "|    ðe (     2, 50.00%) |") {
+		t.Errorf("report is missing the swapped form of the:\n%s", report)
+	}
+
+	if strings.Contains(report, " cat (") {
+		t.Errorf("report contains the unchanged word cat:\n%s", report)
+	}
+
+	// unchanged words still count towards the totals.
+	if got := h.words.count.Value(); got != 4 {
+		t.Errorf("word count = %d, want 4", got)
+	}
+}
+
+func TestOnlyChangedBySwapJSON(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "the cat then the\n")
+
+	_, report := runCount(t, "--format=json", "--only-changed-by-swap", "-s=th,ð", input)
+
+	var r results
+
+	if err := json.Unmarshal([]byte(report), &r); err != nil {
+		t.Fatalf("unmarshalling json: %v\n%s", err, report)
+	}
+
+	values := func(entries []entry) []string {
+		vs := []string{}
+
+		for _, e := range entries {
+			vs = append(vs, e.Value)
+		}
+
+		return vs
+	}
+
+	if got, want := values(r.Words.Raw), []string{"the", "then"}; !slices.Equal(got, want) {
+		t.Errorf("raw words = %v, want %v", got, want)
+	}
+
+	if got, want := values(r.Words.Swapped), []string{"ðe", "ðen"}; !slices.Equal(got, want) {
+		t.Errorf("swapped words = %v, want %v", got, want)
+	}
+
+	// unchanged words still count towards the totals.
+	if r.Words.Totals.Raw != 4 {
+		t.Errorf("raw total = %d, want 4", r.Words.Totals.Raw)
+	}
+}
+
+func TestHumanPrecision(t *testing.T) {
+	defer func(p int) { siPrecision = p }(siPrecision)
+
+	table := []struct {
+		precision int
+		want      string
+	}{
+		{0, "1k"},
+		{1, "1.2k"},
+		{2, "1.23k"},
+		{3, "1.234k"},
+	}
+
+	for _, test := range table {
+		siPrecision = test.precision
+
+		if got := human(1234); got != test.want {
+			t.Errorf("human(1234) at precision %d = %q, want %q", test.precision, got, test.want)
+		}
+	}
+}
+
+func TestSIPrecision(t *testing.T) {
+	defer func(p int) { siPrecision = p }(siPrecision)
+
+	input := writeInput(t, t.TempDir(), "in.txt", strings.Repeat("a ", 1234)+"\n")
+
+	_, report := runCount(t, "--si-precision=2", input)
+
+	if !strings.Contains(report, "| # | raw (1.23k) |") {
+		t.Errorf("report isn't rendered at 2 decimals:\n%s", report)
+	}
+
+	_, report = runCount(t, input)
+
+	if !strings.Contains(report, "| # | raw (1.2k) |") {
+		t.Errorf("report isn't rendered at the default 1 decimal:\n%s", report)
+	}
+}
+
+func TestHideRegex(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "the cat the dog the a\n")
+
+	_, report := runCount(t, "--hide-regex=^(the|a)$", input)
+
+	rows := tableRows(t, report, "words")
+	if want := []string{"cat", "dog"}; !slices.Equal(rows, want) {
+		t.Errorf("words = %v, want %v", rows, want)
+	}
+
+	// hidden words still count towards the totals and percentages.
+	for _, want := range []string{
+		"| # | raw (6) |",
+		"|  0 |   cat (     1, 16.67%) |",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q:\n%s", want, report)
+		}
+	}
+
+	// letters are never hidden.
+	if rows := tableRows(t, report, "letters"); !slices.Contains(rows, "a") {
+		t.Errorf("letters = %v, want a to be shown", rows)
+	}
+}
+
+func TestInitialBigrams(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "thistle the that a\n")
+
+	h, report := runCount(t, "--initial-bigrams", input)
+
+	if got := countOf(h.onsets.universal, "th"); got != 3 {
+		t.Errorf("count of th = %d, want 3", got)
+	}
+
+	// words shorter than two letters have no onset.
+	if got := h.onsets.count.Value(); got != 3 {
+		t.Errorf("onset count = %d, want 3", got)
+	}
+
+	if rows := tableRows(t, report, "initial bigrams"); !slices.Equal(rows, []string{"th"}) {
+		t.Errorf("initial bigrams = %v, want only th", rows)
+	}
+}
+
+func TestOnsetOf(t *testing.T) {
+	table := []struct {
+		word, want string
+	}{
+		{"thistle", "th"},
+		{"a", ""},
+		{"", ""},
+		// é is a single letter, whether composed or not.
+		{"éte", "ét"},
+	}
+
+	for _, test := range table {
+		if got := onsetOf(test.word); got != test.want {
+			t.Errorf("onset of %q = %q, want %q", test.word, got, test.want)
+		}
+	}
+}
+
+func TestFilterLengths(t *testing.T) {
+	words := []string{"a", "an", "the", "words", "cafe\u0301", "antidisestablishment"}
+
+	table := []struct {
+		name     string
+		min, max int
+		want     []string
+	}{
+		{"unset", 0, 0, words},
+		{"min", 2, 0, words[1:]},
+		{"max", 0, 4, []string{"a", "an", "the", "cafe\u0301"}},
+		{"both", 3, 5, []string{"the", "words", "cafe\u0301"}},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			h := newHandler()
+			h.minWordLen = test.min
+			h.maxWordLen = test.max
+
+			if got := h.filterLengths(slices.Clone(words)); !slices.Equal(got, test.want) {
+				t.Errorf("filterLengths = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWordLengthFlags(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "a bb ccc dddddddddd\n")
+
+	h, _ := runCount(t, "--min-word-len=2", "--max-word-len=3", input)
+
+	for word, want := range map[string]int64{"a": 0, "bb": 1, "ccc": 1, "dddddddddd": 0} {
+		if got := countOf(h.words.universal, word); got != want {
+			t.Errorf("count of %q = %d, want %d", word, got, want)
+		}
+	}
+
+	// filtered words don't count towards the letters either.
+	for letter, want := range map[string]int64{"a": 0, "b": 2, "c": 3, "d": 0} {
+		if got := countOf(h.letters.universal, letter); got != want {
+			t.Errorf("count of letter %q = %d, want %d", letter, got, want)
+		}
+	}
+
+	table := []struct {
+		flags   []string
+		wantErr string
+	}{
+		{[]string{"--min-word-len=-1"}, "min-word-len cannot be negative"},
+		{[]string{"--max-word-len=-1"}, "max-word-len cannot be negative"},
+		{[]string{"--min-word-len=4", "--max-word-len=3"}, "max-word-len cannot be less than min-word-len"},
+	}
+
+	for _, test := range table {
+		err := execCount(newHandler(), append(test.flags, input)...)
+		if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("%v: expected error containing %q, got %v", test.flags, test.wantErr, err)
+		}
+	}
+}
+
+func TestRemoveRegex(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "Chapter XIV begins 1865 chapters\n")
+
+	h, _ := runCount(t, "--removeRegex=^[0-9]+$", "--removeRegex=^[ivxlc]+$", input)
+
+	for word, want := range map[string]int64{"xiv": 0, "1865": 0, "chapter": 1, "begins": 1, "chapters": 1} {
+		if got := countOf(h.words.removed, word); got != want {
+			t.Errorf("count of %q after removal = %d, want %d", word, got, want)
+		}
+	}
+
+	if got := h.words.countRemoved.Value(); got != 2 {
+		t.Errorf("removed word count = %d, want 2", got)
+	}
+
+	// words are matched lowercased, even when case sensitive.
+	h, _ = runCount(t, "--case-sensitive", "--removeRegex=^chapter$", input)
+
+	if got := countOf(h.words.removed, "Chapter"); got != 0 {
+		t.Errorf("case-sensitive count of Chapter after removal = %d, want 0", got)
+	}
+
+	err := execCount(newHandler(), "--removeRegex=[", input)
+	if err == nil || !strings.Contains(err.Error(), "compiling removeRegex") {
+		t.Errorf("expected a compile error, got %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"mime"
+	"regexp"
+	"strings"
+)
+
+// mboxExts holds the extensions of mbox mail archives.
+var mboxExts = []string{".mbox", ".mbx"}
+
+// mboxEscapedFromRE matches a body line starting with From, which
+// mbox archives escape with one or more > (ex: >From, >>From).
+var mboxEscapedFromRE = regexp.MustCompile(`^>+From `)
+
+// isMbox is true if the path is an mbox archive.  Archives can also
+// be compressed (ex: .mbox.gz).
+func isMbox(p string) bool {
+	p = strings.ToLower(trimCompressedExt(p))
+
+	for _, ext := range mboxExts {
+		if strings.HasSuffix(p, ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mboxState tracks where a line falls within the messages of an mbox
+// archive.  Only the plain text bodies of messages are counted: their
+// headers, and any attachments or non-text parts of multipart
+// messages, are not.
+type mboxState struct {
+	// whether quoted reply lines (ex: > you wrote) get skipped.
+	skipQuoted bool
+
+	// whether the current line is within the headers of a message,
+	// or of a part of a multipart message.
+	inHeaders bool
+	// whether the body that follows the headers gets counted.
+	skipBody  bool
+	prevBlank bool
+	// the boundaries of the multipart parts in the current message,
+	// innermost last.
+	boundaries []string
+	// the header currently being read, which can be folded across
+	// lines, and the values of the headers describing the body.
+	header      string
+	contentType string
+	encoding    string
+}
+
+func newMboxState(skipQuoted bool) mboxState {
+	return mboxState{
+		skipQuoted: skipQuoted,
+		// any text before the first From line is treated as headers.
+		inHeaders: true,
+		prevBlank: true,
+	}
+}
+
+// next advances the state past the line, and reports whether the line
+// is counted body text.  Escaped From lines in the body have their
+// escaping > removed.
+func (ms *mboxState) next(ln string) (string, bool) {
+	blank := len(strings.TrimSpace(ln)) == 0
+	defer func() { ms.prevBlank = blank }()
+
+	// every message starts with a From line, following a blank line.
+	if ms.prevBlank && strings.HasPrefix(ln, "From ") {
+		*ms = newMboxState(ms.skipQuoted)
+		return ln, false
+	}
+
+	if ms.inHeaders {
+		ms.nextHeader(ln, blank)
+		return ln, false
+	}
+
+	if boundary, closing, ok := ms.boundary(ln); ok {
+		if closing {
+			// the epilogue after the last part.
+			ms.boundaries = ms.boundaries[:len(ms.boundaries)-1]
+			ms.skipBody = true
+		} else {
+			ms.boundaries = append(ms.boundaries[:ms.depth(boundary)], boundary)
+			ms.startHeaders()
+		}
+
+		return ln, false
+	}
+
+	if ms.skipBody {
+		return ln, false
+	}
+
+	// escaped From lines are body text, and never quoted replies.
+	if mboxEscapedFromRE.MatchString(ln) {
+		return ln[1:], true
+	}
+
+	return ln, !ms.skipQuoted || !strings.HasPrefix(strings.TrimLeft(ln, " "), ">")
+}
+
+// startHeaders begins the headers of a message, or of a part.
+func (ms *mboxState) startHeaders() {
+	ms.inHeaders = true
+	ms.header = ""
+	ms.contentType = ""
+	ms.encoding = ""
+}
+
+// nextHeader reads a line of headers.  A blank line ends the headers,
+// and decides whether the body gets counted.
+func (ms *mboxState) nextHeader(ln string, blank bool) {
+	if !blank {
+		switch {
+		// folded headers continue on lines starting with whitespace.
+		case strings.HasPrefix(ln, " ") || strings.HasPrefix(ln, "\t"):
+		default:
+			name, _, _ := strings.Cut(ln, ":")
+			ms.header = strings.ToLower(strings.TrimSpace(name))
+			_, ln, _ = strings.Cut(ln, ":")
+		}
+
+		switch ms.header {
+		case "content-type":
+			ms.contentType += ln
+		case "content-transfer-encoding":
+			ms.encoding += ln
+		}
+
+		return
+	}
+
+	ms.inHeaders = false
+
+	mediaType, params, err := mime.ParseMediaType(ms.contentType)
+	if err != nil {
+		// a missing or malformed content type is assumed to be text.
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") && len(params["boundary"]) > 0 {
+		ms.boundaries = append(ms.boundaries, params["boundary"])
+		// the preamble before the first part.
+		ms.skipBody = true
+
+		return
+	}
+
+	ms.skipBody = mediaType != "text/plain" ||
+		strings.EqualFold(strings.TrimSpace(ms.encoding), "base64")
+}
+
+// boundary reports whether the line delimits one of the current
+// message's parts, and whether it closes the parts of that boundary.
+func (ms *mboxState) boundary(ln string) (string, bool, bool) {
+	if !strings.HasPrefix(ln, "--") {
+		return "", false, false
+	}
+
+	ln = strings.TrimRight(ln, " \t")
+
+	for i := len(ms.boundaries) - 1; i >= 0; i-- {
+		b := ms.boundaries[i]
+
+		switch ln {
+		case "--" + b:
+			return b, false, true
+		case "--" + b + "--":
+			ms.boundaries = ms.boundaries[:i+1]
+			return b, true, true
+		}
+	}
+
+	return "", false, false
+}
+
+// depth produces the count of boundaries enclosing the boundary.
+func (ms *mboxState) depth(boundary string) int {
+	for i, b := range ms.boundaries {
+		if b == boundary {
+			return i
+		}
+	}
+
+	return len(ms.boundaries)
+}
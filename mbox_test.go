@@ -0,0 +1,163 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// mboxBody produces the counted body lines of the mbox text.
+func mboxBody(text string, skipQuoted bool) []string {
+	var (
+		ms   = newMboxState(skipQuoted)
+		body = []string{}
+	)
+
+	for _, ln := range strings.Split(text, "\n") {
+		if ln, ok := ms.next(ln); ok {
+			body = append(body, ln)
+		}
+	}
+
+	return body
+}
+
+func TestMboxState(t *testing.T) {
+	table := []struct {
+		name       string
+		text       string
+		skipQuoted bool
+		want       []string
+	}{
+		{
+			name: "from separators",
+			text: `From alice@example.com Mon Jan  1 00:00:00 2024
+From: alice@example.com
+Subject: first
+
+first body
+From here on, not a separator
+
+From bob@example.com Tue Jan  2 00:00:00 2024
+Subject: second
+
+second body`,
+			want: []string{"first body", "From here on, not a separator", "", "second body"},
+		},
+		{
+			name: "folded headers",
+			text: `From alice@example.com Mon Jan  1 00:00:00 2024
+Subject: a subject
+  folded across lines
+Content-Type: text/plain;
+	charset="utf-8"
+
+body`,
+			want: []string{"body"},
+		},
+		{
+			name: "folded non-text content type",
+			text: `From alice@example.com Mon Jan  1 00:00:00 2024
+Content-Type:
+	text/html
+
+<p>html</p>`,
+			want: []string{},
+		},
+		{
+			name: "multipart boundaries",
+			text: `From alice@example.com Mon Jan  1 00:00:00 2024
+Content-Type: multipart/alternative;
+	boundary="outer"
+
+preamble
+--outer
+Content-Type: text/plain
+
+plain part
+--outer
+Content-Type: text/html
+
+<p>html part</p>
+--outer
+Content-Type: text/plain
+Content-Transfer-Encoding: base64
+
+aGVsbG8=
+--outer--
+epilogue`,
+			want: []string{"plain part"},
+		},
+		{
+			name: "nested multipart boundaries",
+			text: `From alice@example.com Mon Jan  1 00:00:00 2024
+Content-Type: multipart/mixed; boundary=outer
+
+--outer
+Content-Type: multipart/alternative; boundary=inner
+
+--inner
+Content-Type: text/plain
+
+inner part
+--inner--
+--outer
+Content-Type: text/plain
+
+outer part
+--outer--`,
+			want: []string{"inner part", "outer part"},
+		},
+		{
+			name: "escaped from",
+			text: `From alice@example.com Mon Jan  1 00:00:00 2024
+
+>From the start
+>>From the middle
+> quoted`,
+			want: []string{"From the start", ">From the middle", "> quoted"},
+		},
+		{
+			name: "escaped from, skip quoted",
+			text: `From alice@example.com Mon Jan  1 00:00:00 2024
+
+>From the start
+> quoted
+  >> nested quote
+unquoted`,
+			skipQuoted: true,
+			want:       []string{"From the start", "unquoted"},
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			got := mboxBody(test.text, test.skipQuoted)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("body = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMboxInput(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "mail.mbox", `From alice@example.com Mon Jan  1 00:00:00 2024
+Subject: headers
+
+>From body
+> quoted reply
+`)
+
+	h, _ := runCount(t, "--skip-quoted", input)
+
+	for word, want := range map[string]int64{
+		"from":    1,
+		"body":    1,
+		"headers": 0,
+		"quoted":  0,
+	} {
+		if got := countOf(h.words.universal, word); got != want {
+			t.Errorf("count of %s = %d, want %d", word, got, want)
+		}
+	}
+}
@@ -0,0 +1,483 @@
+package main
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/alcionai/clues/cluerr"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// unicodeKeepChars is the regex character class of all characters
+	// kept by normalization by default: the letters of every script,
+	// their combining marks (ex: the accent of a decomposed é), and
+	// ascii digits.
+	unicodeKeepChars = `\p{L}\p{M}0-9`
+	// asciiKeepChars is the regex character class of all characters
+	// kept by normalization in ascii-only mode.
+	asciiKeepChars = `a-zA-Z0-9`
+)
+
+var (
+	keepCharsRE        = keepCharsRegex(unicodeKeepChars)
+	markdownImageRE    = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	markdownLinkRE     = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	markdownCodeRE     = regexp.MustCompile("`[^`]*`")
+	markdownFenceRE    = regexp.MustCompile("^\\s{0,3}(```|~~~)")
+	markdownHeadingRE  = regexp.MustCompile(`^\s{0,3}#{1,6}\s+`)
+	markdownEmphasisRE = regexp.MustCompile(`(\*{1,3}|_{1,3}|~~)`)
+	urlRE              = regexp.MustCompile(`<?https?://\S+`)
+)
+
+// keepCharsRegex matches everything except spaces and the
+// characters in the class.
+func keepCharsRegex(class string) *regexp.Regexp {
+	return regexp.MustCompile(keepCharsPattern(class))
+}
+
+// keepCharsPattern puts the space first, so that a class ending in a
+// literal hyphen (ex: a-z-) doesn't form a range with it.
+func keepCharsPattern(class string) string {
+	return `[^ ` + class + `]+`
+}
+
+// normalizeOpts configures how normalize reduces lines to words.
+type normalizeOpts struct {
+	// drops any token that contains neither a letter nor a digit.
+	requireAlnum bool
+	// replaces each run of stripped characters.  Empty merges the
+	// text on either side, a space splits it into separate words.
+	stripReplacement string
+	// keeps digits from all scripts, not only ascii digits.
+	localeDigits bool
+	// converts digits from all scripts to their ascii equivalent.
+	foldDigits bool
+	// drops markdown images, and reduces markdown links to their text.
+	stripMarkdownLinks bool
+	// reduces markdown to its prose, dropping links, inline code,
+	// urls, heading markers, and emphasis.  Code fences span lines,
+	// so they're dropped while scanning instead.
+	markdown bool
+	// reduces latex source to its prose, dropping comments, inline
+	// math, and commands.  Math environments span lines, so they're
+	// dropped while scanning instead.
+	latex bool
+
+	// strips all non-ascii letters, rather than keeping the letters
+	// of every script.
+	asciiOnly bool
+	// keeps the case of letters, rather than lowercasing all text.
+	caseSensitive bool
+	// when true, text is converted into unicodeForm, so that composed
+	// and decomposed forms of a letter (ex: é and e+◌́) count the same.
+	unicodeNorm bool
+	unicodeForm norm.Form
+	// maps accented letters to their base letter (ex: é to e).
+	foldDiacritics bool
+	// keeps apostrophes within words (ex: don't), rather than
+	// stripping them (ex: dont).
+	keepApostrophes bool
+	// expands contractions into their words (ex: don't to do not).
+	// Implies keepApostrophes.
+	splitContractions bool
+	// what happens to the hyphens within words.  Empty joins them.
+	hyphens hyphenMode
+	// keeps emoji and other symbols.
+	emoji bool
+	// when non-nil, splits han, hiragana, and katakana text into
+	// the dictionary's words, since those scripts don't space
+	// their words.
+	cjkDict *cjkDict
+	// splits identifiers into their words (ex: camelCase and
+	// snake_case into camel case and snake case).
+	splitIdentifiers bool
+	// when populated, the regex character class of the characters
+	// kept by normalization, in place of the default class.
+	// Validated by parseKeepChars.
+	keepClass string
+
+	// the compiled keep-chars pass.  When nil, the unicode
+	// default is used.
+	keepChars *regexp.Regexp
+}
+
+// compile builds the keep-chars pass for the current options.
+func (opts *normalizeOpts) compile() {
+	class := unicodeKeepChars
+
+	switch {
+	case len(opts.keepClass) > 0:
+		class = opts.keepClass
+	case opts.asciiOnly:
+		class = asciiKeepChars
+	}
+
+	if opts.localeDigits {
+		class += `\p{Nd}`
+	}
+
+	if opts.keepsApostrophes() {
+		class += `'`
+	}
+
+	if opts.emoji {
+		class += emojiKeepChars
+	}
+
+	if opts.hyphens == hyphensKeep {
+		class += `\-`
+	}
+
+	opts.keepChars = keepCharsRegex(class)
+}
+
+// keepsApostrophes is true if apostrophes survive the keep-chars pass.
+func (opts normalizeOpts) keepsApostrophes() bool {
+	return opts.keepApostrophes || opts.splitContractions
+}
+
+// lowers (unless case sensitive) and strips most non-alpha-numeric characters.
+func normalize(
+	ln string,
+	opts normalizeOpts,
+) (
+	[]string, // the revised text
+	bool, // whether the original text ended in a dash-broken word.
+	int, // the count of non-whitespace characters stripped from the text.
+) {
+	// first to ensure we catch broken words.
+	ln = strings.TrimSpace(ln)
+
+	if len(ln) == 0 {
+		return nil, false, 0
+	}
+
+	if opts.unicodeNorm {
+		ln = opts.unicodeForm.String(ln)
+	}
+
+	if opts.foldDiacritics {
+		ln = foldDiacritics(ln)
+	}
+
+	original := countNonSpace(ln)
+
+	ln = typographyReplacer.Replace(ln)
+
+	broken := len(ln) > 1 &&
+		strings.HasSuffix(ln, "-") &&
+		string(ln[len(ln)-2]) != ""
+
+	// before lowering, which loses the camel case boundaries.
+	if opts.splitIdentifiers {
+		ln = splitIdentifiers(ln)
+	}
+
+	if !opts.caseSensitive {
+		ln = strings.ToLower(ln)
+	}
+
+	ln = strings.TrimSpace(ln)
+
+	if opts.stripMarkdownLinks || opts.markdown {
+		// images first, since their syntax contains a link.
+		ln = markdownImageRE.ReplaceAllString(ln, "")
+		ln = markdownLinkRE.ReplaceAllString(ln, "$1")
+	}
+
+	if opts.markdown {
+		ln = stripMarkdown(ln)
+	}
+
+	if opts.latex {
+		ln = stripLatex(ln)
+	}
+
+	if opts.foldDigits {
+		ln = strings.Map(foldDigit, ln)
+	}
+
+	ln = hyphenate(ln, opts.hyphens)
+
+	keep := keepCharsRE
+	if opts.keepChars != nil {
+		keep = opts.keepChars
+	}
+
+	ln = keep.ReplaceAllString(ln, opts.stripReplacement)
+
+	words := strings.Fields(ln)
+
+	if opts.keepsApostrophes() {
+		words = trimApostrophes(words)
+	}
+
+	if opts.requireAlnum {
+		words = slices.DeleteFunc(words, func(word string) bool {
+			return !hasAlnum(word)
+		})
+	}
+
+	// measured before expanding contractions, which add letters
+	// (ex: won't to will not) that were never in the text.
+	stripped := original - countNonSpace(strings.Join(words, ""))
+
+	if opts.splitContractions {
+		words = splitContractions(words)
+	}
+
+	if opts.cjkDict != nil {
+		words = opts.cjkDict.segment(words)
+	}
+
+	return words, broken, stripped
+}
+
+// parseKeepChars checks that the user-supplied class (ex: a-zåäö')
+// is the contents of a single regex character class.  Brackets must
+// be escaped, other than in posix classes (ex: [:alpha:]), so that
+// the class can't escape its own brackets in keepCharsRegex.
+func parseKeepChars(class string) (string, error) {
+	if len(class) == 0 {
+		return "", cluerr.New("keep-chars cannot be empty")
+	}
+
+	for i := 0; i < len(class); i++ {
+		switch {
+		case class[i] == '\\':
+			i++
+		case strings.HasPrefix(class[i:], "[:"):
+			end := strings.Index(class[i:], ":]")
+			if end < 0 {
+				return "", cluerr.New("keep-chars has an unclosed posix class").
+					With("input", class)
+			}
+
+			i += end + 1
+		case class[i] == '[' || class[i] == ']':
+			return "", cluerr.New("keep-chars cannot contain unescaped brackets").
+				With("input", class)
+		}
+	}
+
+	if _, err := regexp.Compile(keepCharsPattern(class)); err != nil {
+		return "", cluerr.Wrap(err, "compiling keep-chars").
+			With("input", class)
+	}
+
+	return class, nil
+}
+
+// unicodeForms maps the names of unicode normalization forms to
+// their forms.
+var unicodeForms = map[string]norm.Form{
+	"nfc":  norm.NFC,
+	"nfd":  norm.NFD,
+	"nfkc": norm.NFKC,
+	"nfkd": norm.NFKD,
+}
+
+// parseUnicodeForm parses the name of a unicode normalization form,
+// ignoring case.
+func parseUnicodeForm(s string) (norm.Form, error) {
+	form, ok := unicodeForms[strings.ToLower(s)]
+	if !ok {
+		return 0, cluerr.New("unicode-norm must be one of: NFC, NFD, NFKC, NFKD").
+			With("input", s)
+	}
+
+	return form, nil
+}
+
+// foldDiacritics decomposes the letters in ln, and drops their
+// combining marks, leaving only the base letters.  Letters that
+// don't decompose (ex: ß, ø, ð) are kept as they are.
+func foldDiacritics(ln string) string {
+	ln = strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+
+		return r
+	}, norm.NFD.String(ln))
+
+	return norm.NFC.String(ln)
+}
+
+// typographyReplacer converts typographic characters into their
+// ascii equivalents, so that (ex:) curly and straight apostrophes
+// normalize the same.  Dashes separate words rather than joining
+// them, as does a double hyphen standing in for a dash.
+var typographyReplacer = strings.NewReplacer(
+	"’", "'",
+	"‘", "'",
+	"‛", "'",
+	"ʼ", "'",
+	"“", `"`,
+	"”", `"`,
+	"„", `"`,
+	"‐", "-",
+	"‑", "-",
+	"–", " ",
+	"—", " ",
+	"―", " ",
+	"--", " ",
+	"…", "...",
+)
+
+// trimApostrophes drops the apostrophes at either end of the words,
+// which are nearly always quotation marks (ex: 'hello'), along with
+// any words that were only apostrophes.
+func trimApostrophes(words []string) []string {
+	trimmed := words[:0]
+
+	for _, word := range words {
+		if word = strings.Trim(word, "'"); len(word) > 0 {
+			trimmed = append(trimmed, word)
+		}
+	}
+
+	return trimmed
+}
+
+// contractionSuffixes maps the endings of contractions to the word
+// they stand for.  's is left alone, since it's as often a possessive
+// (ex: john's) as a contraction of is or has.
+var contractionSuffixes = []struct {
+	suffix string
+	word   string
+}{
+	{"n't", "not"},
+	{"'re", "are"},
+	{"'ve", "have"},
+	{"'ll", "will"},
+	{"'d", "would"},
+	{"'m", "am"},
+}
+
+// irregularContractions don't contract to their own first word.
+var irregularContractions = map[string][]string{
+	"can't":   {"can", "not"},
+	"won't":   {"will", "not"},
+	"shan't":  {"shall", "not"},
+	"ain't":   {"is", "not"},
+	"let's":   {"let", "us"},
+	"y'all":   {"you", "all"},
+	"o'clock": {"o'clock"},
+}
+
+// splitContractions expands every contraction in words into the
+// words it stands for (ex: don't becomes do, not).
+func splitContractions(words []string) []string {
+	split := make([]string, 0, len(words))
+
+	for _, word := range words {
+		if expanded, ok := irregularContractions[word]; ok {
+			split = append(split, expanded...)
+			continue
+		}
+
+		for _, c := range contractionSuffixes {
+			if stem, ok := strings.CutSuffix(word, c.suffix); ok && len(stem) > 0 {
+				split = append(split, stem)
+				word = c.word
+
+				break
+			}
+		}
+
+		split = append(split, word)
+	}
+
+	return split
+}
+
+// splitIdentifiers adds a space at every underscore, and at every
+// camel case boundary: wherever a lowercase letter or digit is followed
+// by an uppercase letter (ex: camelCase), and before the last capital
+// of an acronym that starts a new word (ex: HTTPServer).
+func splitIdentifiers(ln string) string {
+	var (
+		sb    strings.Builder
+		runes = []rune(ln)
+	)
+
+	sb.Grow(len(ln))
+
+	for i, r := range runes {
+		if r == '_' {
+			sb.WriteRune(' ')
+			continue
+		}
+
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			acronym := unicode.IsUpper(prev) &&
+				i < len(runes)-1 &&
+				unicode.IsLower(runes[i+1])
+
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || acronym {
+				sb.WriteRune(' ')
+			}
+		}
+
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
+
+// stripMarkdown removes the inline markdown syntax, and any urls,
+// from the line, so that only its prose remains.
+func stripMarkdown(ln string) string {
+	ln = markdownCodeRE.ReplaceAllString(ln, " ")
+	ln = urlRE.ReplaceAllString(ln, " ")
+	ln = markdownHeadingRE.ReplaceAllString(ln, "")
+
+	return markdownEmphasisRE.ReplaceAllString(ln, "")
+}
+
+// foldDigit converts a decimal digit in any script into its ascii
+// equivalent.  All other runes are returned unchanged.  Unicode
+// guarantees that decimal digits are encoded in contiguous runs of
+// 0-9, so each range in the Nd table is a series of those runs.
+func foldDigit(r rune) rune {
+	if r <= unicode.MaxASCII || !unicode.IsDigit(r) {
+		return r
+	}
+
+	for _, rg := range unicode.Nd.R16 {
+		if r <= 0xFFFF && uint16(r) >= rg.Lo && uint16(r) <= rg.Hi {
+			return '0' + (r-rune(rg.Lo))%10
+		}
+	}
+
+	for _, rg := range unicode.Nd.R32 {
+		if uint32(r) >= rg.Lo && uint32(r) <= rg.Hi {
+			return '0' + (r-rune(rg.Lo))%10
+		}
+	}
+
+	return r
+}
+
+// hasAlnum is true if the word contains at least one letter or digit.
+func hasAlnum(word string) bool {
+	return strings.ContainsFunc(word, isAlnum)
+}
+
+// countNonSpace counts the runes in ln that aren't whitespace.
+func countNonSpace(ln string) int {
+	var n int
+
+	for _, r := range ln {
+		if !unicode.IsSpace(r) {
+			n++
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,653 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestNormalizeRequireAlnum(t *testing.T) {
+	table := []struct {
+		name         string
+		ln           string
+		opts         normalizeOpts
+		want         []string
+		wantStripped int
+	}{
+		{
+			name:         "keep hyphens",
+			ln:           "well-known --- text",
+			opts:         normalizeOpts{hyphens: hyphensKeep, requireAlnum: true},
+			want:         []string{"well-known", "text"},
+			wantStripped: 3,
+		},
+		{
+			name:         "kept hyphen",
+			ln:           "a --- b",
+			opts:         normalizeOpts{keepClass: `a-z\-`},
+			want:         []string{"a", "-", "b"},
+			wantStripped: 2,
+		},
+		{
+			name:         "kept hyphen, require alnum",
+			ln:           "a --- b",
+			opts:         normalizeOpts{keepClass: `a-z\-`, requireAlnum: true},
+			want:         []string{"a", "b"},
+			wantStripped: 3,
+		},
+		{
+			name:         "emoji",
+			ln:           "i ♥ go",
+			opts:         normalizeOpts{emoji: true},
+			want:         []string{"i", "♥", "go"},
+			wantStripped: 0,
+		},
+		{
+			name:         "emoji, require alnum",
+			ln:           "i ♥ go",
+			opts:         normalizeOpts{emoji: true, requireAlnum: true},
+			want:         []string{"i", "go"},
+			wantStripped: 1,
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			test.opts.compile()
+
+			words, _, stripped := normalize(test.ln, test.opts)
+
+			if !slices.Equal(words, test.want) {
+				t.Errorf("words = %q, want %q", words, test.want)
+			}
+
+			if stripped != test.wantStripped {
+				t.Errorf("stripped = %d, want %d", stripped, test.wantStripped)
+			}
+		})
+	}
+}
+
+func TestNormalizeStripReplacement(t *testing.T) {
+	table := []struct {
+		name        string
+		replacement string
+		want        []string
+	}{
+		{"merge", "", []string{"cooperate"}},
+		{"split", " ", []string{"co", "operate"}},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			opts := normalizeOpts{stripReplacement: test.replacement}
+			opts.compile()
+
+			words, _, stripped := normalize("co*operate", opts)
+
+			if !slices.Equal(words, test.want) {
+				t.Errorf("words = %q, want %q", words, test.want)
+			}
+
+			if stripped != 1 {
+				t.Errorf("stripped = %d, want 1", stripped)
+			}
+		})
+	}
+}
+
+func TestStripReplacementInvalid(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "co*operate\n")
+
+	if err := execCount(newHandler(), "--strip-replacement=_", input); err == nil {
+		t.Error("expected an error for a strip-replacement other than empty or a space")
+	}
+}
+
+func TestNormalizeLocaleDigits(t *testing.T) {
+	table := []struct {
+		name string
+		opts normalizeOpts
+		want []string
+	}{
+		{"ascii digits only", normalizeOpts{}, []string{"room"}},
+		{"locale digits", normalizeOpts{localeDigits: true}, []string{"room", "٣٤"}},
+		{"folded", normalizeOpts{localeDigits: true, foldDigits: true}, []string{"room", "34"}},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			test.opts.compile()
+
+			words, _, _ := normalize("room ٣٤", test.opts)
+
+			if !slices.Equal(words, test.want) {
+				t.Errorf("words = %q, want %q", words, test.want)
+			}
+		})
+	}
+}
+
+func TestFoldDigit(t *testing.T) {
+	table := []struct {
+		in, want rune
+	}{
+		{'٣', '3'},
+		{'९', '9'},
+		{'𝟘', '0'},
+		{'7', '7'},
+		{'a', 'a'},
+		{'Ⅳ', 'Ⅳ'},
+	}
+
+	for _, test := range table {
+		if got := foldDigit(test.in); got != test.want {
+			t.Errorf("foldDigit(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestCountLocaleDigits(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "٣٤ ٣\n")
+
+	h, _ := runCount(t, "--locale-digits", input)
+
+	if got := countOf(h.letters.universal, "٣"); got != 2 {
+		t.Errorf("count of ٣ = %d, want 2", got)
+	}
+
+	h, _ = runCount(t, "--locale-digits", "--fold-digits", input)
+
+	if got := countOf(h.letters.universal, "3"); got != 2 {
+		t.Errorf("count of folded 3 = %d, want 2", got)
+	}
+
+	if got := countOf(h.words.universal, "34"); got != 1 {
+		t.Errorf("count of folded 34 = %d, want 1", got)
+	}
+}
+
+func TestNormalizeStripMarkdownLinks(t *testing.T) {
+	table := []struct {
+		name string
+		ln   string
+		want []string
+	}{
+		{"link", "see [the docs](http://x)", []string{"see", "the", "docs"}},
+		{"image", "a ![logo](http://x/logo.png) here", []string{"a", "here"}},
+		{"image in a link", "[![badge](http://x/b.svg)](http://x) ok", []string{"ok"}},
+		{"brackets", "an [aside] (too)", []string{"an", "aside", "too"}},
+	}
+
+	opts := normalizeOpts{stripMarkdownLinks: true}
+	opts.compile()
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			words, _, _ := normalize(test.ln, opts)
+
+			if !slices.Equal(words, test.want) {
+				t.Errorf("words = %q, want %q", words, test.want)
+			}
+		})
+	}
+}
+
+func TestCountStripMarkdownLinks(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "see [the docs](http://x)\n")
+
+	h, _ := runCount(t, "--strip-markdown-links", input)
+
+	if got := countOf(h.words.universal, "docs"); got != 1 {
+		t.Errorf("count of docs = %d, want 1", got)
+	}
+
+	for _, word := range []string{"http", "x", "httpx"} {
+		if got := countOf(h.words.universal, word); got != 0 {
+			t.Errorf("count of url word %q = %d, want 0", word, got)
+		}
+	}
+}
+
+func TestNormalizeMarkdown(t *testing.T) {
+	table := []struct {
+		name string
+		ln   string
+		want []string
+	}{
+		{"heading", "## a heading", []string{"a", "heading"}},
+		{"emphasis", "some *very* __bold__ ~~old~~ text", []string{"some", "very", "bold", "old", "text"}},
+		{"inline code", "call `fmt.Println(x)` here", []string{"call", "here"}},
+		{"link", "see [the docs](https://x.dev/docs)", []string{"see", "the", "docs"}},
+		{"bare url", "visit https://example.com/path today", []string{"visit", "today"}},
+		{"autolink", "visit <https://example.com/path> today", []string{"visit", "today"}},
+		{"image", "![diagram](img/arch.png) shown", []string{"shown"}},
+		{"hashtag", "not a #heading", []string{"not", "a", "heading"}},
+	}
+
+	opts := normalizeOpts{markdown: true}
+	opts.compile()
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			words, _, _ := normalize(test.ln, opts)
+
+			if !slices.Equal(words, test.want) {
+				t.Errorf("words = %q, want %q", words, test.want)
+			}
+		})
+	}
+}
+
+func TestCountMarkdown(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "readme.md", "# title\n"+
+		"prose with `code` and a [link](https://example.com)\n"+
+		"```go\n"+
+		"func fenced() {}\n"+
+		"```\n"+
+		"~~~\n"+
+		"tilde fenced\n"+
+		"~~~\n"+
+		"more prose\n")
+
+	h, _ := runCount(t, "--markdown", "--ext=md", input)
+
+	for word, want := range map[string]int64{
+		"title":   1,
+		"prose":   2,
+		"link":    1,
+		"code":    0,
+		"example": 0,
+		"func":    0,
+		"fenced":  0,
+		"go":      0,
+	} {
+		if got := countOf(h.words.universal, word); got != want {
+			t.Errorf("count of %s = %d, want %d", word, got, want)
+		}
+	}
+}
+
+func TestNormalizeUnicodeLetters(t *testing.T) {
+	table := []struct {
+		name string
+		opts normalizeOpts
+		want []string
+	}{
+		{"every script", normalizeOpts{}, []string{"ðe", "caf\u00e9", "straße", "мир", "λόγος", "42"}},
+		{"ascii only", normalizeOpts{asciiOnly: true}, []string{"e", "caf", "strae", "42"}},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			test.opts.compile()
+
+			words, _, _ := normalize("Ðe café, Straße! мир λόγος 42", test.opts)
+
+			if !slices.Equal(words, test.want) {
+				t.Errorf("words = %q, want %q", words, test.want)
+			}
+		})
+	}
+}
+
+func TestCountUnicodeLetters(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "ðe then\n")
+
+	h, _ := runCount(t, "-s=th,ð", input)
+
+	if got := countOf(h.letters.universal, "ð"); got != 1 {
+		t.Errorf("raw count of ð = %d, want 1", got)
+	}
+
+	if got := countOf(h.letters.swapped, "ð"); got != 2 {
+		t.Errorf("swapped count of ð = %d, want 2", got)
+	}
+
+	h, _ = runCount(t, "--ascii-only", input)
+
+	if got := countOf(h.letters.universal, "ð"); got != 0 {
+		t.Errorf("ascii-only count of ð = %d, want 0", got)
+	}
+}
+
+func TestParseUnicodeForm(t *testing.T) {
+	for name, want := range map[string]norm.Form{
+		"NFC":  norm.NFC,
+		"nfd":  norm.NFD,
+		"NfKc": norm.NFKC,
+		"NFKD": norm.NFKD,
+	} {
+		got, err := parseUnicodeForm(name)
+		if err != nil {
+			t.Errorf("parsing %q: %v", name, err)
+			continue
+		}
+
+		if got != want {
+			t.Errorf("parseUnicodeForm(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	if _, err := parseUnicodeForm("NFX"); err == nil {
+		t.Error("expected an error for an unknown form")
+	}
+}
+
+func TestNormalizeUnicodeNorm(t *testing.T) {
+	const (
+		composed   = "caf\u00e9"
+		decomposed = "cafe\u0301"
+	)
+
+	table := []struct {
+		name string
+		opts normalizeOpts
+		want []string
+	}{
+		{"unset", normalizeOpts{}, []string{composed, decomposed}},
+		{"nfc", normalizeOpts{unicodeNorm: true, unicodeForm: norm.NFC}, []string{composed, composed}},
+		{"nfd", normalizeOpts{unicodeNorm: true, unicodeForm: norm.NFD}, []string{decomposed, decomposed}},
+		{"nfkc", normalizeOpts{unicodeNorm: true, unicodeForm: norm.NFKC}, []string{composed, composed}},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			test.opts.compile()
+
+			words, _, _ := normalize(composed+" "+decomposed, test.opts)
+
+			if !slices.Equal(words, test.want) {
+				t.Errorf("words = %q, want %q", words, test.want)
+			}
+		})
+	}
+}
+
+func TestCountUnicodeNorm(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "caf\u00e9 cafe\u0301 \ufb01sh\n")
+
+	h, _ := runCount(t, "--unicode-norm=NFC", input)
+
+	if got := countOf(h.words.universal, "caf\u00e9"); got != 2 {
+		t.Errorf("count of café = %d, want 2", got)
+	}
+
+	h, _ = runCount(t, "--unicode-norm=NFKC", input)
+
+	if got := countOf(h.words.universal, "fish"); got != 1 {
+		t.Errorf("count of the folded ligature fish = %d, want 1", got)
+	}
+
+	err := execCount(newHandler(), "--unicode-norm=NFX", input)
+	if err == nil || !strings.Contains(err.Error(), "unicode-norm must be one of") {
+		t.Errorf("expected an unsupported form error, got %v", err)
+	}
+}
+
+func TestFoldDiacritics(t *testing.T) {
+	table := []struct {
+		in, want string
+	}{
+		{"café", "cafe"},
+		{"café", "cafe"},
+		{"niño", "nino"},
+		{"Ångström", "Angstrom"},
+		{"straße", "straße"},
+		{"ørsted", "ørsted"},
+		{"ðe", "ðe"},
+		{"plain", "plain"},
+	}
+
+	for _, test := range table {
+		if got := foldDiacritics(test.in); got != test.want {
+			t.Errorf("foldDiacritics(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestCountFoldDiacritics(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "élève eleve Niño\n")
+
+	h, _ := runCount(t, "--fold-diacritics", input)
+
+	for word, want := range map[string]int64{"eleve": 2, "élève": 0, "nino": 1} {
+		if got := countOf(h.words.universal, word); got != want {
+			t.Errorf("count of %q = %d, want %d", word, got, want)
+		}
+	}
+
+	if got := countOf(h.letters.universal, "é"); got != 0 {
+		t.Errorf("count of é = %d, want 0", got)
+	}
+
+	h, _ = runCount(t, input)
+
+	if got := countOf(h.words.universal, "élève"); got != 1 {
+		t.Errorf("unfolded count of élève = %d, want 1", got)
+	}
+}
+
+func TestNormalizeApostrophes(t *testing.T) {
+	table := []struct {
+		name string
+		opts normalizeOpts
+		want []string
+	}{
+		{"stripped", normalizeOpts{}, []string{"dont", "say", "hello", "to", "johns", "dog"}},
+		{"kept", normalizeOpts{keepApostrophes: true}, []string{"don't", "say", "hello", "to", "john's", "dog"}},
+		{"split", normalizeOpts{splitContractions: true}, []string{"do", "not", "say", "hello", "to", "john's", "dog"}},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			test.opts.compile()
+
+			words, _, _ := normalize("Don’t say 'hello' to John's dog '", test.opts)
+
+			if !slices.Equal(words, test.want) {
+				t.Errorf("words = %q, want %q", words, test.want)
+			}
+		})
+	}
+}
+
+func TestSplitContractions(t *testing.T) {
+	table := []struct {
+		in   []string
+		want []string
+	}{
+		{[]string{"don't"}, []string{"do", "not"}},
+		{[]string{"won't", "can't"}, []string{"will", "not", "can", "not"}},
+		{[]string{"they're", "we've", "you'll"}, []string{"they", "are", "we", "have", "you", "will"}},
+		{[]string{"i'd", "i'm"}, []string{"i", "would", "i", "am"}},
+		{[]string{"let's", "o'clock"}, []string{"let", "us", "o'clock"}},
+		{[]string{"it's", "john's"}, []string{"it's", "john's"}},
+		{[]string{"n't", "plain"}, []string{"n't", "plain"}},
+	}
+
+	for _, test := range table {
+		if got := splitContractions(test.in); !slices.Equal(got, test.want) {
+			t.Errorf("splitContractions(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestCountContractions(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "I don't know, they won't say\n")
+
+	h, _ := runCount(t, "--keep-apostrophes", input)
+
+	for word, want := range map[string]int64{"don't": 1, "won't": 1, "dont": 0} {
+		if got := countOf(h.words.universal, word); got != want {
+			t.Errorf("count of %q = %d, want %d", word, got, want)
+		}
+	}
+
+	h, _ = runCount(t, "--split-contractions", input)
+
+	for word, want := range map[string]int64{"do": 1, "will": 1, "not": 2, "don't": 0} {
+		if got := countOf(h.words.universal, word); got != want {
+			t.Errorf("split count of %q = %d, want %d", word, got, want)
+		}
+	}
+}
+
+func TestNormalizeTypography(t *testing.T) {
+	table := []struct {
+		name string
+		ln   string
+		opts normalizeOpts
+		want []string
+	}{
+		{"curly apostrophe", "it’s it's", normalizeOpts{keepApostrophes: true}, []string{"it's", "it's"}},
+		{"curly quotes", "“quoted” ‘words’", normalizeOpts{}, []string{"quoted", "words"}},
+		{"em dash", "word—word", normalizeOpts{}, []string{"word", "word"}},
+		{"en dash", "pages 10–12", normalizeOpts{}, []string{"pages", "10", "12"}},
+		{"double hyphen", "wait--what", normalizeOpts{}, []string{"wait", "what"}},
+		{"unicode hyphen", "well‐known", normalizeOpts{hyphens: hyphensKeep}, []string{"well-known"}},
+		{"ellipsis", "and…then", normalizeOpts{stripReplacement: " "}, []string{"and", "then"}},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			test.opts.compile()
+
+			words, _, _ := normalize(test.ln, test.opts)
+
+			if !slices.Equal(words, test.want) {
+				t.Errorf("words = %q, want %q", words, test.want)
+			}
+		})
+	}
+}
+
+func TestCountTypography(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "don’t don't—ever\n")
+
+	h, _ := runCount(t, "--keep-apostrophes", input)
+
+	for word, want := range map[string]int64{"don't": 2, "ever": 1, "don'tever": 0} {
+		if got := countOf(h.words.universal, word); got != want {
+			t.Errorf("count of %q = %d, want %d", word, got, want)
+		}
+	}
+}
+
+func TestParseKeepChars(t *testing.T) {
+	table := []struct {
+		name    string
+		class   string
+		wantErr string
+	}{
+		{"letters", "a-zåäö'", ""},
+		{"posix class", "[:alpha:]'", ""},
+		{"escaped bracket", `a-z\]`, ""},
+		{"trailing hyphen", "a-z-", ""},
+		{"empty", "", "cannot be empty"},
+		{"unescaped bracket", "a-z]", "unescaped brackets"},
+		{"escaping the class", "a-z][^", "unescaped brackets"},
+		{"unclosed posix class", "[:alpha", "unclosed posix class"},
+		{"invalid range", "z-a", "compiling keep-chars"},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseKeepChars(test.class)
+
+			if len(test.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("parsing %q: %v", test.class, err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("expected error containing %q, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNormalizeKeepClass(t *testing.T) {
+	table := []struct {
+		name string
+		opts normalizeOpts
+		want []string
+	}{
+		{"letters only", normalizeOpts{keepClass: "a-zåäö"}, []string{"smörgåsbord", "mw"}},
+		{"with digits and hyphens", normalizeOpts{keepClass: "a-zåäö0-9-", hyphens: hyphensKeep}, []string{"smörgåsbord", "m-25w"}},
+		{"ascii letters", normalizeOpts{keepClass: "a-z"}, []string{"smrgsbord", "mw"}},
+		{"overrides ascii only", normalizeOpts{keepClass: "a-zåäö", asciiOnly: true}, []string{"smörgåsbord", "mw"}},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			test.opts.compile()
+
+			words, _, _ := normalize("Smörgåsbord M-25W", test.opts)
+
+			if !slices.Equal(words, test.want) {
+				t.Errorf("words = %q, want %q", words, test.want)
+			}
+		})
+	}
+}
+
+func TestCountKeepChars(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "ǫld 42 ðe\n")
+
+	h, _ := runCount(t, "--keep-chars=a-zǫ", input)
+
+	for word, want := range map[string]int64{"ǫld": 1, "42": 0, "e": 1} {
+		if got := countOf(h.words.universal, word); got != want {
+			t.Errorf("count of %q = %d, want %d", word, got, want)
+		}
+	}
+
+	err := execCount(newHandler(), "--keep-chars=a-z]", input)
+	if err == nil || !strings.Contains(err.Error(), "unescaped brackets") {
+		t.Errorf("expected an unescaped bracket error, got %v", err)
+	}
+}
+
+func TestSplitIdentifiers(t *testing.T) {
+	table := []struct {
+		in, want string
+	}{
+		{"camelCaseWords", "camel Case Words"},
+		{"snake_case_words", "snake case words"},
+		{"HTTPServer", "HTTP Server"},
+		{"parseURL2Fast", "parse URL2 Fast"},
+		{"userID", "user ID"},
+		{"Plain words", "Plain words"},
+		{"ALLCAPS", "ALLCAPS"},
+	}
+
+	for _, test := range table {
+		if got := splitIdentifiers(test.in); got != test.want {
+			t.Errorf("splitIdentifiers(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestCountSplitIdentifiers(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.go", "func parseFlags(flag_val string) // readsFlags\n")
+
+	h, _ := runCount(t, "--split-identifiers", "--strip-replacement= ", "--any-file", input)
+
+	for word, want := range map[string]int64{"parse": 1, "flags": 2, "flag": 1, "val": 1, "reads": 1, "parseflags": 0} {
+		if got := countOf(h.words.universal, word); got != want {
+			t.Errorf("count of %q = %d, want %d", word, got, want)
+		}
+	}
+
+	h, _ = runCount(t, "--strip-replacement= ", "--any-file", input)
+
+	if got := countOf(h.words.universal, "parseflags"); got != 1 {
+		t.Errorf("unsplit count of parseflags = %d, want 1", got)
+	}
+}
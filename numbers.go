@@ -0,0 +1,66 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// numberMode decides how purely numeric words get counted.
+type numberMode string
+
+const (
+	// counts numbers as words.
+	numbersKeep numberMode = "keep"
+	// drops numbers entirely.
+	numbersDrop numberMode = "drop"
+	// counts numbers in their own table, instead of as words.
+	numbersSeparate numberMode = "separate"
+)
+
+// numberModes holds all supported number modes.
+var numberModes = []numberMode{
+	numbersKeep,
+	numbersDrop,
+	numbersSeparate,
+}
+
+func numberModeNames() []string {
+	names := make([]string, 0, len(numberModes))
+
+	for _, m := range numberModes {
+		names = append(names, string(m))
+	}
+
+	return names
+}
+
+// isNumber is true if the word is made of nothing but digits.  The
+// separators within numbers (ex: 1,865.50) are already stripped by
+// normalization.
+func isNumber(word string) bool {
+	return len(word) > 0 && !strings.ContainsFunc(word, func(r rune) bool {
+		return !unicode.IsDigit(r)
+	})
+}
+
+// divertNumbers removes the numbers from the line's words, unless
+// they're kept.  Separated numbers get counted in the numbers table
+// instead.  Numbers never count towards the letters.
+func (h *handler) divertNumbers(ln []string) []string {
+	if h.numbers == numbersKeep {
+		return ln
+	}
+
+	return slices.DeleteFunc(ln, func(word string) bool {
+		if !isNumber(word) {
+			return false
+		}
+
+		if h.numbers == numbersSeparate {
+			inc(&h.numberStats, word, word, false)
+		}
+
+		return true
+	})
+}
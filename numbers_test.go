@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsNumber(t *testing.T) {
+	for word, want := range map[string]bool{
+		"1865": true,
+		"٣٤":   true,
+		"":     false,
+		"1st":  false,
+		"abc":  false,
+	} {
+		if got := isNumber(word); got != want {
+			t.Errorf("isNumber(%q) = %v, want %v", word, got, want)
+		}
+	}
+}
+
+func TestNumbersInput(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "in 1865 the 1st war\n")
+
+	table := []struct {
+		mode      string
+		words     map[string]int64
+		number    int64
+		digitOnes int64
+	}{
+		{"keep", map[string]int64{"1865": 1, "1st": 1, "war": 1}, 0, 2},
+		{"drop", map[string]int64{"1865": 0, "1st": 1, "war": 1}, 0, 1},
+		{"separate", map[string]int64{"1865": 0, "1st": 1, "war": 1}, 1, 1},
+	}
+
+	for _, test := range table {
+		t.Run(test.mode, func(t *testing.T) {
+			h, report := runCount(t, "--numbers="+test.mode, input)
+
+			for word, want := range test.words {
+				if got := countOf(h.words.universal, word); got != want {
+					t.Errorf("count of %q = %d, want %d", word, got, want)
+				}
+			}
+
+			if got := countOf(h.numberStats.universal, "1865"); got != test.number {
+				t.Errorf("numbers count of 1865 = %d, want %d", got, test.number)
+			}
+
+			// numbers kept out of the words stay out of the letters.
+			if got := countOf(h.letters.universal, "1"); got != test.digitOnes {
+				t.Errorf("letters count of 1 = %d, want %d", got, test.digitOnes)
+			}
+
+			if hasTable := strings.Contains(report, "\nnumbers\n"); hasTable != (test.mode == "separate") {
+				t.Errorf("report has a numbers table: %v, in:\n%s", hasTable, report)
+			}
+		})
+	}
+
+	err := execCount(newHandler(), "--numbers=count", input)
+	if err == nil || !strings.Contains(err.Error(), "unsupported numbers mode") {
+		t.Errorf("expected an unsupported mode error, got %v", err)
+	}
+}
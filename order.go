@@ -0,0 +1,102 @@
+package main
+
+import (
+	"cmp"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/alcionai/clues/cluerr"
+)
+
+type fileOrder string
+
+const (
+	orderNone  fileOrder = "none"
+	orderName  fileOrder = "name"
+	orderSize  fileOrder = "size"
+	orderMTime fileOrder = "mtime"
+)
+
+// fileOrders holds all supported file orders.
+var fileOrders = []fileOrder{
+	orderNone,
+	orderName,
+	orderSize,
+	orderMTime,
+}
+
+func fileOrderNames() []string {
+	names := make([]string, 0, len(fileOrders))
+
+	for _, o := range fileOrders {
+		names = append(names, string(o))
+	}
+
+	return names
+}
+
+// sortFiles orders the paths in place.  Sizes and modification times
+// are ascending, and ties fall back to the path name so that the
+// order is stable between runs.  orderNone keeps the given order.
+func sortFiles(paths []string, order fileOrder) error {
+	if order == orderNone {
+		return nil
+	}
+
+	if order == orderName {
+		slices.Sort(paths)
+		return nil
+	}
+
+	infos := make(map[string]os.FileInfo, len(paths))
+
+	for _, p := range paths {
+		// stdin and urls have no size or mtime, and sort as the zero value.
+		if !isLocal(p) {
+			continue
+		}
+
+		info, err := os.Stat(p)
+		if err != nil {
+			return cluerr.Wrap(err, "checking file: "+p)
+		}
+
+		infos[p] = info
+	}
+
+	size := func(p string) int64 {
+		if info, ok := infos[p]; ok {
+			return info.Size()
+		}
+
+		return 0
+	}
+
+	mtime := func(p string) time.Time {
+		if info, ok := infos[p]; ok {
+			return info.ModTime()
+		}
+
+		return time.Time{}
+	}
+
+	slices.SortStableFunc(paths, func(a, b string) int {
+		var diff int
+
+		switch order {
+		case orderSize:
+			diff = cmp.Compare(size(a), size(b))
+		case orderMTime:
+			diff = mtime(a).Compare(mtime(b))
+		}
+
+		if diff != 0 {
+			return diff
+		}
+
+		return cmp.Compare(a, b)
+	})
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSortFiles(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		large  = writeInput(t, dir, "a.txt", "the largest file of them all\n")
+		small  = writeInput(t, dir, "b.txt", "tiny\n")
+		medium = writeInput(t, dir, "c.txt", "a middling file\n")
+		now    = time.Now()
+	)
+
+	// modified from newest to oldest, by name.
+	for i, p := range []string{large, small, medium} {
+		mtime := now.Add(-time.Duration(i) * time.Hour)
+
+		if err := os.Chtimes(p, mtime, mtime); err != nil {
+			t.Fatalf("setting mtime: %v", err)
+		}
+	}
+
+	table := []struct {
+		order fileOrder
+		want  []string
+	}{
+		{orderNone, []string{medium, large, small}},
+		{orderName, []string{large, small, medium}},
+		{orderSize, []string{small, medium, large}},
+		{orderMTime, []string{medium, small, large}},
+	}
+
+	for _, test := range table {
+		t.Run(string(test.order), func(t *testing.T) {
+			paths := []string{medium, large, small}
+
+			if err := sortFiles(paths, test.order); err != nil {
+				t.Fatalf("sorting files: %v", err)
+			}
+
+			if !slices.Equal(paths, test.want) {
+				t.Errorf("order = %v, want %v", paths, test.want)
+			}
+		})
+	}
+}
+
+func TestOrderSize(t *testing.T) {
+	var (
+		dir   = t.TempDir()
+		large = writeInput(t, dir, "a.txt", "the largest file of them all\n")
+		small = writeInput(t, dir, "b.txt", "tiny\n")
+	)
+
+	_, report := runCount(t, "--order=size", "--per-file", large, small)
+
+	first, second := strings.Index(report, "words: "+small), strings.Index(report, "words: "+large)
+	if first < 0 || second < 0 {
+		t.Fatalf("report is missing a file table:\n%s", report)
+	}
+
+	if first > second {
+		t.Errorf("the smaller file wasn't counted first:\n%s", report)
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"unicode/utf8"
+
+	"github.com/puzpuzpuz/xsync/v4"
+)
+
+// fileCounts holds the stats of a single input, for reporting
+// each input separately in per-file mode.
+type fileCounts struct {
+	path    string
+	words   stats
+	letters stats
+}
+
+func newFileCounts(path string) *fileCounts {
+	return &fileCounts{
+		path:    path,
+		words:   makeStats(),
+		letters: makeStats(),
+	}
+}
+
+// tracksFiles is true if stats are tracked separately for each input.
+func (h *handler) tracksFiles() bool {
+	return h.perFile || h.uniqueGrowth || h.retainsFiles()
+}
+
+// retainsFiles is true if each input's stats are held until the
+// end of the run.
+func (h *handler) retainsFiles() bool {
+	return h.reportsFiles() || h.normalizePerFile || h.commonWord
+}
+
+// reportsFiles is true if each input's stats are included in
+// the final report.
+func (h *handler) reportsFiles() bool {
+	return h.perFile && !h.interleave
+}
+
+// startFile begins tracking per-file stats for the input at path.
+// Does nothing unless stats are tracked per file.
+func (h *handler) startFile(path string) {
+	if h.tracksFiles() {
+		h.file = newFileCounts(path)
+	}
+}
+
+// finishFile completes the per-file stats of the current input.
+// When interleaving, the stats are written immediately to w.
+// Otherwise they're held until the final report.
+func (h *handler) finishFile(w io.Writer) {
+	if h.file == nil {
+		return
+	}
+
+	if h.uniqueGrowth {
+		h.growth = append(h.growth, h.measureGrowth(h.file))
+	}
+
+	if h.perFile && h.interleave {
+		h.printFile(h.file, w)
+	}
+
+	if h.retainsFiles() {
+		h.files = append(h.files, h.file)
+	}
+
+	h.file = nil
+}
+
+// mergeNormalized adds every file's stats onto the total, weighting
+// each file so that all files contribute equally, regardless of
+// their size.  Each file is scaled up to the size of the largest
+// file, so that counts remain on the same order as the raw counts.
+func (h *handler) mergeNormalized() {
+	var maxWords, maxLetters int64
+
+	for _, fc := range h.files {
+		maxWords = max(maxWords, fc.words.count.Value())
+		maxLetters = max(maxLetters, fc.letters.count.Value())
+	}
+
+	for _, fc := range h.files {
+		addScaled(&h.words, fc.words, maxWords)
+		addScaled(&h.letters, fc.letters, maxLetters)
+	}
+}
+
+// addScaled adds the src stats onto dst, multiplying every count
+// by size/src.count.  Stats that counted nothing are skipped.
+func addScaled(dst *stats, src stats, size int64) {
+	n := src.count.Value()
+	if n == 0 {
+		return
+	}
+
+	weight := float64(size) / float64(n)
+
+	scale := func(v int64) int64 {
+		return int64(math.Round(float64(v) * weight))
+	}
+
+	dst.count.Add(scale(src.count.Value()))
+	dst.countSwapped.Add(scale(src.countSwapped.Value()))
+	dst.countRemoved.Add(scale(src.countRemoved.Value()))
+	dst.countBoth.Add(scale(src.countBoth.Value()))
+	dst.countChanged.Add(scale(src.countChanged.Value()))
+
+	for _, pair := range []struct {
+		dst, src *xsync.Map[string, *xsync.Counter]
+	}{
+		{dst.universal, src.universal},
+		{dst.swapped, src.swapped},
+		{dst.removed, src.removed},
+		{dst.both, src.both},
+	} {
+		pair.src.Range(func(key string, value *xsync.Counter) bool {
+			v, _ := pair.dst.LoadOrCompute(key, func() (*xsync.Counter, bool) {
+				return xsync.NewCounter(), false
+			})
+
+			v.Add(scale(value.Value()))
+
+			return true
+		})
+	}
+}
+
+// printFile writes the word and letter tables of a single input.
+func (h *handler) printFile(fc *fileCounts, w io.Writer) {
+	for _, sec := range h.fileSections(fc) {
+		sec.print(w)
+		writeLn(w, " ")
+	}
+}
+
+// fileSections produces the word and letter tables of a single input.
+func (h *handler) fileSections(fc *fileCounts) []tableSection {
+	return []tableSection{
+		{table: func(w io.Writer) {
+			print(fc.words, "words: "+fc.path, h.wordsView(h.largestTopWords()), w)
+		}},
+		{table: func(w io.Writer) {
+			print(fc.letters, "letters: "+fc.path, h.lettersView(), w)
+		}},
+	}
+}
+
+// incWord counts a word into the total, and per-file, stats.
+// When normalizing per file, the totals are instead produced by
+// mergeNormalized after all files are counted.
+func (h *handler) incWord(
+	ctx context.Context,
+	raw, swapped string,
+	removed bool,
+) {
+	switch {
+	case h.normalizePerFile:
+	case h.sketched(ctx, raw):
+		h.incSketched(raw, removed)
+	default:
+		inc(&h.words, raw, swapped, removed)
+	}
+
+	if h.file != nil {
+		inc(&h.file.words, raw, swapped, removed)
+	}
+}
+
+// incLetter counts a letter into the total, and per-file, stats.
+// When normalizing per file, the totals are instead produced by
+// mergeNormalized after all files are counted.
+func (h *handler) incLetter(raw, swapped string, removed bool) {
+	if !h.normalizePerFile {
+		inc(&h.letters, raw, swapped, removed)
+	}
+
+	if h.file != nil {
+		inc(&h.file.letters, raw, swapped, removed)
+	}
+}
+
+// longestCommonWord produces the longest raw word that appears in
+// every file.  Ties are broken alphabetically.  Returns false if
+// the files share no words.
+func longestCommonWord(files []*fileCounts) (string, bool) {
+	if len(files) == 0 {
+		return "", false
+	}
+
+	var (
+		longest string
+		found   bool
+	)
+
+	files[0].words.universal.Range(func(word string, _ *xsync.Counter) bool {
+		for _, fc := range files[1:] {
+			if _, ok := fc.words.universal.Load(word); !ok {
+				return true
+			}
+		}
+
+		n, ln := utf8.RuneCountInString(word), utf8.RuneCountInString(longest)
+		if !found || n > ln || (n == ln && word < longest) {
+			longest, found = word, true
+		}
+
+		return true
+	})
+
+	return longest, found
+}
+
+// printLongestCommonWord writes a footer line with the longest raw
+// word shared by every file.
+func printLongestCommonWord(files []*fileCounts, w io.Writer) {
+	word, ok := longestCommonWord(files)
+	if !ok {
+		writeLn(w, "longest common word: none")
+		return
+	}
+
+	writeLn(w, fmt.Sprintf(
+		"longest common word: %s (%d letters, in all %d files)",
+		word,
+		utf8.RuneCountInString(word),
+		len(files),
+	))
+}
+
+// fileGrowth is the vocabulary a file added to those before it.
+type fileGrowth struct {
+	path string
+	// the count of distinct words in the file.
+	unique int
+	// the count of distinct words not seen in any earlier file.
+	added int
+	// the count of distinct words across this and all earlier files.
+	cumulative int
+}
+
+// measureGrowth adds the file's words to the words seen so far, and
+// reports how many of them were new.
+func (h *handler) measureGrowth(fc *fileCounts) fileGrowth {
+	g := fileGrowth{path: fc.path}
+
+	fc.words.universal.Range(func(word string, _ *xsync.Counter) bool {
+		g.unique++
+
+		if _, ok := h.growthSeen[word]; !ok {
+			h.growthSeen[word] = struct{}{}
+			g.added++
+		}
+
+		return true
+	})
+
+	g.cumulative = len(h.growthSeen)
+
+	return g
+}
+
+// printUniqueGrowth writes the count of new unique words contributed
+// by each file, in the order the files were counted.
+func printUniqueGrowth(growth []fileGrowth, w io.Writer) {
+	writeLn(w, "unique word growth")
+	writeLn(w, "| file | unique | new | cumulative |")
+	writeLn(w, "|---|---|---|---|")
+
+	for _, g := range growth {
+		writeLn(w, fmt.Sprintf(
+			"| %s | %6s | %6s (%2.2f%%) | %6s |",
+			g.path,
+			human(g.unique),
+			human(g.added),
+			percent(g.added, int64(g.unique)),
+			human(g.cumulative),
+		))
+	}
+}
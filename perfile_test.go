@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestInterleaveOutput(t *testing.T) {
+	var (
+		dir = t.TempDir()
+		b   = writeInput(t, dir, "b.txt", "beta\n")
+		a   = writeInput(t, dir, "a.txt", "alpha\n")
+	)
+
+	_, report := runCount(t, "--per-file", "--interleave-output", b, a)
+
+	var (
+		first  = strings.Index(report, "words: "+b+"\n")
+		second = strings.Index(report, "words: "+a+"\n")
+		final  = strings.Index(report, "\nwords\n")
+	)
+
+	if first < 0 || second < 0 || final < 0 {
+		t.Fatalf("report is missing a file table, or the final table:\n%s", report)
+	}
+
+	if first > second || second > final {
+		t.Errorf("file tables aren't in processing order, ahead of the final table:\n%s", report)
+	}
+}
+
+func TestInterleaveOutputStreams(t *testing.T) {
+	var (
+		ctx = context.Background()
+		h   = newHandler()
+		out bytes.Buffer
+	)
+
+	h.perFile = true
+	h.interleave = true
+	h.reportTo = &out
+
+	if err := h.runReader(ctx, "first.txt", strings.NewReader("alpha\n")); err != nil {
+		t.Fatalf("counting first file: %v", err)
+	}
+
+	// the first file's table is written before the next file is read.
+	if !strings.Contains(out.String(), "words: first.txt\n") {
+		t.Errorf("first file's table wasn't written once it was counted:\n%s", out.String())
+	}
+
+	if err := h.runReader(ctx, "second.txt", strings.NewReader("beta\n")); err != nil {
+		t.Fatalf("counting second file: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "words: second.txt\n") {
+		t.Errorf("second file's table wasn't written once it was counted:\n%s", out.String())
+	}
+
+	if len(h.files) != 0 {
+		t.Errorf("interleaved files were held for the final report: %d", len(h.files))
+	}
+}
+
+func TestInterleaveOutputRequiresPerFile(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "word\n")
+
+	if err := execCount(newHandler(), "--interleave-output", input); err == nil {
+		t.Error("expected an error for interleave-output without per-file")
+	}
+}
+
+func TestNormalizePerFile(t *testing.T) {
+	var (
+		dir   = t.TempDir()
+		large = writeInput(t, dir, "large.txt", strings.Repeat("big ", 90)+"\n")
+		small = writeInput(t, dir, "small.txt", strings.Repeat("tiny ", 9)+"big\n")
+	)
+
+	h, _ := runCount(t, "--normalize-per-file", large, small)
+
+	// the small file is scaled up to the size of the large file.
+	if got := countOf(h.words.universal, "tiny"); got != 81 {
+		t.Errorf("count of tiny = %d, want 81", got)
+	}
+
+	if got := countOf(h.words.universal, "big"); got != 99 {
+		t.Errorf("count of big = %d, want 99", got)
+	}
+
+	if got := h.words.count.Value(); got != 180 {
+		t.Errorf("word count = %d, want 180", got)
+	}
+
+	h, _ = runCount(t, large, small)
+
+	if got := countOf(h.words.universal, "tiny"); got != 9 {
+		t.Errorf("unweighted count of tiny = %d, want 9", got)
+	}
+}
+
+func TestLongestCommonWord(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		first  = writeInput(t, dir, "first.txt", "the elephant and a giraffe\n")
+		second = writeInput(t, dir, "second.txt", "a giraffe, the zebra, and an extraordinarily long word\n")
+	)
+
+	_, report := runCount(t, "--longest-common-word", first, second)
+
+	if want := "longest common word: giraffe (7 letters, in all 2 files)\n"; !strings.Contains(report, want) {
+		t.Errorf("report is missing %q:\n%s", want, report)
+	}
+}
+
+func TestLongestCommonWordTies(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		files []*fileCounts
+	)
+
+	for _, words := range [][]string{{"bear", "lion", "cat"}, {"lion", "bear", "ox"}} {
+		h := newHandler()
+		h.file = newFileCounts("in.txt")
+		h.processLine(ctx, words)
+
+		files = append(files, h.file)
+	}
+
+	if word, ok := longestCommonWord(files); !ok || word != "bear" {
+		t.Errorf("longest common word = %q, %v, want bear", word, ok)
+	}
+
+	files[1] = newFileCounts("empty.txt")
+
+	if word, ok := longestCommonWord(files); ok {
+		t.Errorf("longest common word = %q, want none", word)
+	}
+}
+
+func TestUniqueGrowth(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		first  = writeInput(t, dir, "first.txt", "the cat sat on a mat\n")
+		second = writeInput(t, dir, "second.txt", "the cat and the hat\n")
+	)
+
+	h, report := runCount(t, "--measure-unique-growth-per-file", first, second)
+
+	want := []fileGrowth{
+		{path: first, unique: 6, added: 6, cumulative: 6},
+		{path: second, unique: 4, added: 2, cumulative: 8},
+	}
+
+	if !slices.Equal(h.growth, want) {
+		t.Errorf("growth = %+v, want %+v", h.growth, want)
+	}
+
+	for _, row := range []string{
+		"| " + first + " |      6 |      6 (100.00%) |      6 |",
+		"| " + second + " |      4 |      2 (50.00%) |      8 |",
+	} {
+		if !strings.Contains(report, row) {
+			t.Errorf("report is missing %q:\n%s", row, report)
+		}
+	}
+}
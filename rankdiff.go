@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/alcionai/clues/cluerr"
+	"github.com/spf13/cobra"
+)
+
+var flagValRankDiffTop int
+
+func newRankDiff() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rank-diff before.json after.json",
+		Short: "print how the rank of each top word changed between two saved states",
+		Long: `Compares two states written by --save-state, and prints how the
+rank of each of the top words changed from the first state to the second.
+
+Words that rose in rank are marked with ↑, and words that fell with ↓.
+Words that weren't counted at all in the first state are marked new, and
+words that fell out of the top words are listed as dropped.
+
+Example: count rank-diff --top=20 january.json.gz february.json.gz`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			before, err := readState(args[0])
+			if err != nil {
+				return cluerr.WrapWC(ctx, err, "loading state: "+args[0])
+			}
+
+			after, err := readState(args[1])
+			if err != nil {
+				return cluerr.WrapWC(ctx, err, "loading state: "+args[1])
+			}
+
+			printRankDiff(
+				rankDiff(before.Words.Universal, after.Words.Universal, flagValRankDiffTop),
+				cmd.OutOrStdout(),
+			)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(
+		&flagValRankDiffTop,
+		"top",
+		10,
+		"the number of top words to compare. ex --top=20",
+	)
+
+	return cmd
+}
+
+// rankChange is the rank of a word in each of two states.  A rank
+// of -1 means the word wasn't within the compared ranks.
+type rankChange struct {
+	word          string
+	before, after int
+	// whether the word was counted at all in the first state.
+	counted bool
+}
+
+// label describes the movement of the word between the two ranks.
+func (rc rankChange) label() string {
+	switch {
+	case rc.after < 0:
+		return "dropped"
+	case !rc.counted:
+		return "new"
+	case rc.before > rc.after:
+		return "↑" + strconv.Itoa(rc.before-rc.after)
+	case rc.before < rc.after:
+		return "↓" + strconv.Itoa(rc.after-rc.before)
+	default:
+		return "="
+	}
+}
+
+// rankDiff produces the rank change of each of the top words in after,
+// followed by each of the top words in before that are no longer in
+// the top words of after.
+func rankDiff(before, after map[string]int64, top int) []rankChange {
+	var (
+		beforeUnits = toRankedUnits(before)
+		afterUnits  = toRankedUnits(after)
+		beforeRanks = make(map[string]int, len(beforeUnits))
+		changes     = []rankChange{}
+	)
+
+	for i, u := range beforeUnits {
+		beforeRanks[u.v] = i
+	}
+
+	if top > 0 && len(afterUnits) > top {
+		afterUnits = afterUnits[:top]
+	}
+
+	if top > 0 && len(beforeUnits) > top {
+		beforeUnits = beforeUnits[:top]
+	}
+
+	inAfter := make(map[string]struct{}, len(afterUnits))
+
+	for i, u := range afterUnits {
+		inAfter[u.v] = struct{}{}
+
+		rank, counted := beforeRanks[u.v]
+		if !counted {
+			rank = -1
+		}
+
+		changes = append(changes, rankChange{
+			word:    u.v,
+			before:  rank,
+			after:   i,
+			counted: counted,
+		})
+	}
+
+	for i, u := range beforeUnits {
+		if _, ok := inAfter[u.v]; ok {
+			continue
+		}
+
+		changes = append(changes, rankChange{
+			word:    u.v,
+			before:  i,
+			after:   -1,
+			counted: true,
+		})
+	}
+
+	return changes
+}
+
+// toRankedUnits sorts the counts into units in rank order.
+func toRankedUnits(m map[string]int64) []unit {
+	units := make([]unit, 0, len(m))
+
+	for k, n := range m {
+		units = append(units, unit{k, int(n)})
+	}
+
+	sortUnits(units)
+
+	return units
+}
+
+func printRankDiff(changes []rankChange, w io.Writer) {
+	rank := func(r int) string {
+		if r < 0 {
+			return "-"
+		}
+
+		return strconv.Itoa(r)
+	}
+
+	writeLn(w, "rank changes")
+	writeLn(w, "| word | before | after | change |")
+	writeLn(w, "|---|---|---|---|")
+
+	for _, rc := range changes {
+		writeLn(w, fmt.Sprintf(
+			"| %5s | %6s | %5s | %7s |",
+			rc.word,
+			rank(rc.before),
+			rank(rc.after),
+			rc.label(),
+		))
+	}
+}
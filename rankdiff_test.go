@@ -0,0 +1,50 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRankDiff(t *testing.T) {
+	var (
+		before = map[string]int64{"the": 10, "cat": 5, "dog": 3, "fish": 1}
+		after  = map[string]int64{"fish": 20, "the": 10, "cat": 5, "newt": 4, "dog": 1}
+		got    []string
+	)
+
+	for _, rc := range rankDiff(before, after, 4) {
+		got = append(got, rc.word+" "+rc.label())
+	}
+
+	want := []string{"fish ↑3", "the ↓1", "cat ↓1", "newt new", "dog dropped"}
+	if !slices.Equal(got, want) {
+		t.Errorf("rank changes = %q, want %q", got, want)
+	}
+}
+
+func TestRankDiffSubcommand(t *testing.T) {
+	var (
+		dir      = t.TempDir()
+		before   = filepath.Join(dir, "before.json.gz")
+		after    = filepath.Join(dir, "after.json")
+		inBefore = writeInput(t, dir, "before.txt", "the the the cat cat rose\n")
+		inAfter  = writeInput(t, dir, "after.txt", "rose rose rose rose the the cat\n")
+	)
+
+	runCount(t, "--save-state="+before, inBefore)
+	runCount(t, "--save-state="+after, inAfter)
+
+	out := runSubcommand(t, "rank-diff", "--top=3", before, after)
+
+	for _, row := range []string{
+		"|  rose |      2 |     0 |      ↑2 |",
+		"|   the |      0 |     1 |      ↓1 |",
+		"|   cat |      1 |     2 |      ↓1 |",
+	} {
+		if !strings.Contains(out, row) {
+			t.Errorf("rank diff is missing %q:\n%s", row, out)
+		}
+	}
+}
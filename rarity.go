@@ -0,0 +1,86 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/puzpuzpuz/xsync/v4"
+)
+
+// rarityScore is the average rarity of a word's letters.
+type rarityScore struct {
+	word  string
+	score float64
+}
+
+// letterRarity scores each word by the average inverse frequency of
+// its letters, as measured across all counted letters.  Letters
+// that were never counted (ex: denied classes) are left out of the
+// average.  Scores are ordered from rarest to most common.
+func letterRarity(
+	words, letters *xsync.Map[string, *xsync.Counter],
+	totalLetters int64,
+) []rarityScore {
+	scores := []rarityScore{}
+
+	if totalLetters == 0 {
+		return scores
+	}
+
+	words.Range(func(word string, _ *xsync.Counter) bool {
+		var (
+			sum float64
+			n   int
+		)
+
+		for _, char := range graphemes(word) {
+			c, ok := letters.Load(char)
+			if !ok || c.Value() == 0 {
+				continue
+			}
+
+			sum += float64(totalLetters) / float64(c.Value())
+			n++
+		}
+
+		if n > 0 {
+			scores = append(scores, rarityScore{word, sum / float64(n)})
+		}
+
+		return true
+	})
+
+	slices.SortFunc(scores, func(a, b rarityScore) int {
+		if diff := cmp.Compare(b.score, a.score); diff != 0 {
+			return diff
+		}
+
+		return strings.Compare(a.word, b.word)
+	})
+
+	return scores
+}
+
+// printLetterRarity writes the top words with the rarest letters.
+func printLetterRarity(
+	words, letters stats,
+	top int,
+	rankLabel string,
+	w io.Writer,
+) {
+	scores := letterRarity(words.universal, letters.universal, letters.count.Value())
+	if len(scores) > top {
+		scores = scores[:top]
+	}
+
+	writeLn(w, "rarest-lettered words")
+	writeLn(w, addRankHeader(rankLabel)+"| word | rarity |")
+	writeLn(w, "|---|---|---|")
+
+	for i, s := range scores {
+		writeLn(w, fmt.Sprintf("| %2d | %5s | %.2f |", i, s.word, s.score))
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLetterRarity(t *testing.T) {
+	h := newHandler()
+	h.processLine(context.Background(), []string{"eat", "tea", "ate", "eta", "quiz"})
+
+	scores := letterRarity(h.words.universal, h.letters.universal, h.letters.count.Value())
+
+	var order []string
+
+	for _, s := range scores {
+		order = append(order, s.word)
+	}
+
+	// quiz's letters each appear once, while e, a, and t appear 4 times.
+	if want := []string{"quiz", "ate", "eat", "eta", "tea"}; !slices.Equal(order, want) {
+		t.Errorf("rarity order = %v, want %v", order, want)
+	}
+
+	if scores[0].score != 16 || scores[1].score != 4 {
+		t.Errorf("scores = %v, want quiz at 16 and ate at 4", scores[:2])
+	}
+}
+
+func TestLetterRarityReport(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "eat tea ate eta quiz\n")
+
+	_, report := runCount(t, "--letter-rarity=1", input)
+
+	if !strings.Contains(report, "rarest-lettered words\n| # | word | rarity |\n|---|---|---|\n|  0 |  quiz | 16.00 |\n") {
+		t.Errorf("report is missing the rarest word:\n%s", report)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/alcionai/clues/cluerr"
+	"github.com/puzpuzpuz/xsync/v4"
+	"gopkg.in/yaml.v3"
+)
+
+type outputFormat string
+
+const (
+	formatTable outputFormat = "table"
+	formatYAML  outputFormat = "yaml"
+	formatJSON  outputFormat = "json"
+	formatCSV   outputFormat = "csv"
+	formatGFM   outputFormat = "gfm"
+)
+
+// formats holds all supported output formats.
+var formats = []outputFormat{
+	formatTable,
+	formatYAML,
+	formatJSON,
+	formatCSV,
+	formatGFM,
+}
+
+func formatNames() []string {
+	names := make([]string, 0, len(formats))
+
+	for _, f := range formats {
+		names = append(names, string(f))
+	}
+
+	return names
+}
+
+// report writes the final results in the configured format.
+func (h *handler) report(w io.Writer) error {
+	switch h.format {
+	case formatYAML:
+		return writeYAML(h.toReport(), w)
+	case formatJSON:
+		return writeJSON(h.toReport(), w)
+	case formatCSV:
+		return writeCSV(h.toReport(), h.delimiter, w)
+	case formatGFM:
+		h.writeGFM(w)
+	default:
+		h.printTables(w)
+	}
+
+	return nil
+}
+
+// results is the structured form of all stats, used by the
+// machine-readable outputs.  Fields are declared in the order
+// they should be written, so that output stays stable.
+type results struct {
+	Words   section       `json:"words" yaml:"words"`
+	Letters section       `json:"letters" yaml:"letters"`
+	Files   []fileSection `json:"files,omitempty" yaml:"files,omitempty"`
+}
+
+// fileSection holds the stats of a single input in per-file mode.
+type fileSection struct {
+	Path    string  `json:"path" yaml:"path"`
+	Words   section `json:"words" yaml:"words"`
+	Letters section `json:"letters" yaml:"letters"`
+}
+
+type section struct {
+	// true if some counts were approximated.
+	Approximate bool    `json:"approximate,omitempty" yaml:"approximate,omitempty"`
+	Totals      totals  `json:"totals" yaml:"totals"`
+	Raw         []entry `json:"raw" yaml:"raw"`
+	Removed     []entry `json:"removed" yaml:"removed"`
+	Swapped     []entry `json:"swapped" yaml:"swapped"`
+	Both        []entry `json:"both" yaml:"both"`
+}
+
+type totals struct {
+	Raw     int64 `json:"raw" yaml:"raw"`
+	Removed int64 `json:"removed" yaml:"removed"`
+	Swapped int64 `json:"swapped" yaml:"swapped"`
+	Both    int64 `json:"both" yaml:"both"`
+}
+
+type entry struct {
+	Value   string  `json:"value" yaml:"value"`
+	Count   int     `json:"count" yaml:"count"`
+	Percent float64 `json:"percent" yaml:"percent"`
+}
+
+func (h *handler) toReport() results {
+	r := results{
+		Words:   toSection(h.words, h.wordsView(h.largestTopWords())),
+		Letters: toSection(h.letters, h.lettersView()),
+	}
+
+	r.Words.Approximate = h.sketch != nil
+
+	if !h.reportsFiles() {
+		return r
+	}
+
+	for _, fc := range h.files {
+		r.Files = append(r.Files, fileSection{
+			Path:    fc.path,
+			Words:   toSection(fc.words, h.wordsView(h.largestTopWords())),
+			Letters: toSection(fc.letters, h.lettersView()),
+		})
+	}
+
+	return r
+}
+
+func toSection(stats stats, v view) section {
+	t := totals{
+		Raw:     stats.count.Value(),
+		Removed: stats.count.Value() - stats.countRemoved.Value(),
+		Swapped: stats.countSwapped.Value(),
+		Both:    stats.countBoth.Value(),
+	}
+
+	return section{
+		Totals:  t,
+		Raw:     toEntries(stats.universal, v, false, t.Raw),
+		Removed: toEntries(stats.removed, v, false, t.Removed),
+		Swapped: toEntries(stats.swapped, v, true, t.Swapped),
+		Both:    toEntries(stats.both, v, true, t.Both),
+	}
+}
+
+func toEntries(
+	counter *xsync.Map[string, *xsync.Counter],
+	v view,
+	swapped bool,
+	total int64,
+) []entry {
+	units := v.apply(v.onlyChanged(toUnitSlice(counter), swapped))
+	entries := make([]entry, 0, len(units))
+
+	for _, u := range units {
+		entries = append(entries, entry{
+			Value:   u.v,
+			Count:   u.n,
+			Percent: percent(u.n, total),
+		})
+	}
+
+	return entries
+}
+
+func percent(n int, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return (float64(n) / float64(total)) * 100
+}
+
+func writeYAML(r results, w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(r); err != nil {
+		return cluerr.Wrap(err, "encoding yaml")
+	}
+
+	return cluerr.Wrap(enc.Close(), "closing yaml encoder").OrNil()
+}
+
+func writeJSON(r results, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return cluerr.Wrap(enc.Encode(r), "encoding json").OrNil()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestFormatYAML(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "the cat the\n")
+
+	_, report := runCount(t, "--format=yaml", "-r=cat", input)
+
+	var r results
+
+	if err := yaml.Unmarshal([]byte(report), &r); err != nil {
+		t.Fatalf("unmarshalling yaml: %v\n%s", err, report)
+	}
+
+	wantWords := []entry{
+		{Value: "the", Count: 2, Percent: 200.0 / 3},
+		{Value: "cat", Count: 1, Percent: 100.0 / 3},
+	}
+
+	if len(r.Words.Raw) != len(wantWords) {
+		t.Fatalf("raw words = %v, want %v", r.Words.Raw, wantWords)
+	}
+
+	for i, want := range wantWords {
+		got := r.Words.Raw[i]
+		if got.Value != want.Value || got.Count != want.Count || math.Abs(got.Percent-want.Percent) > 0.01 {
+			t.Errorf("raw word %d = %v, want %v", i, got, want)
+		}
+	}
+
+	if want := (totals{Raw: 3, Removed: 2, Swapped: 3, Both: 2}); r.Words.Totals != want {
+		t.Errorf("word totals = %v, want %v", r.Words.Totals, want)
+	}
+
+	if len(r.Words.Removed) != 1 || r.Words.Removed[0].Value != "the" {
+		t.Errorf("removed words = %v, want only the", r.Words.Removed)
+	}
+
+	if r.Letters.Totals.Raw != 9 {
+		t.Errorf("raw letters total = %d, want 9", r.Letters.Totals.Raw)
+	}
+
+	if first := r.Letters.Raw[0]; first.Value != "t" || first.Count != 3 {
+		t.Errorf("most common letter = %v, want t with 3", first)
+	}
+}
+
+func TestFormatJSON(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "the cat the\n")
+
+	_, report := runCount(t, "--format=json", input)
+
+	var r results
+
+	if err := json.Unmarshal([]byte(report), &r); err != nil {
+		t.Fatalf("unmarshalling json: %v\n%s", err, report)
+	}
+
+	if r.Words.Totals.Raw != 3 || r.Words.Raw[0].Value != "the" || r.Words.Raw[0].Count != 2 {
+		t.Errorf("words = %+v, want 3 words, led by the with 2", r.Words)
+	}
+}
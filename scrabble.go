@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/puzpuzpuz/xsync/v4"
+)
+
+// scrabblePoints holds the point value of each letter in the
+// standard english edition of scrabble.  Any other letter,
+// including digits and swapped letters, is worth nothing.
+var scrabblePoints = map[string]int64{
+	"a": 1, "e": 1, "i": 1, "l": 1, "n": 1,
+	"o": 1, "r": 1, "s": 1, "t": 1, "u": 1,
+	"d": 2, "g": 2,
+	"b": 3, "c": 3, "m": 3, "p": 3,
+	"f": 4, "h": 4, "v": 4, "w": 4, "y": 4,
+	"k": 5,
+	"j": 8, "x": 8,
+	"q": 10, "z": 10,
+}
+
+// scrabbleScore sums the points of every letter occurrence.  Letters
+// score the same in either case.
+func scrabbleScore(letters *xsync.Map[string, *xsync.Counter]) int64 {
+	var score int64
+
+	letters.Range(func(key string, value *xsync.Counter) bool {
+		score += scrabblePoints[strings.ToLower(key)] * value.Value()
+		return true
+	})
+
+	return score
+}
+
+// printScrabble writes the total scrabble score of each letters
+// column, along with the average score per word in that column.
+func printScrabble(
+	words, letters stats,
+	w io.Writer,
+) {
+	var (
+		scores = []int64{
+			scrabbleScore(letters.universal),
+			scrabbleScore(letters.removed),
+			scrabbleScore(letters.swapped),
+			scrabbleScore(letters.both),
+		}
+		counts = []int64{
+			words.count.Value(),
+			words.count.Value() - words.countRemoved.Value(),
+			words.countSwapped.Value(),
+			words.countBoth.Value(),
+		}
+		totalLn   = "| total "
+		averageLn = "| average "
+	)
+
+	for i, score := range scores {
+		var avg float64
+		if counts[i] > 0 {
+			avg = float64(score) / float64(counts[i])
+		}
+
+		totalLn += fmt.Sprintf("| %s ", human(score))
+		averageLn += fmt.Sprintf("| %.2f ", avg)
+	}
+
+	writeLn(w, "scrabble score")
+	writeLn(w, "|  | raw | removed | swapped | both |")
+	writeLn(w, "|---|---|---|---|---|")
+	writeLn(w, totalLn+"|")
+	writeLn(w, averageLn+"|")
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestScrabbleScore(t *testing.T) {
+	h := newHandler()
+
+	// quiz scores 22, and jet scores 10.
+	h.processLine(context.Background(), []string{"quiz", "jet"})
+
+	if got := scrabbleScore(h.letters.universal); got != 32 {
+		t.Errorf("score = %d, want 32", got)
+	}
+}
+
+func TestScrabbleScoreIgnoresCase(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "QUIZ jet\n")
+
+	h, report := runCount(t, "--scrabble-score", "--case-sensitive", input)
+
+	if got := scrabbleScore(h.letters.universal); got != 32 {
+		t.Errorf("score = %d, want 32", got)
+	}
+
+	if !strings.Contains(report, "| average | 16.00 |") {
+		t.Errorf("report is missing the average score of 16:\n%s", report)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/alcionai/clues/clog"
+	"github.com/alcionai/clues/cluerr"
+)
+
+const (
+	// maxTextEntropy is the highest byte entropy, in bits per byte,
+	// expected of text.  Prose in most languages and encodings sits
+	// well below 6; compressed or encrypted data approaches 8.
+	maxTextEntropy = 7.5
+	// minEntropySample is the fewest bytes needed for the entropy
+	// to be meaningful.  Smaller samples can't reach a high entropy.
+	minEntropySample = 512
+	// maxControlRatio is the highest share of control bytes, other
+	// than whitespace, expected of text.
+	maxControlRatio = 0.1
+)
+
+// byteEntropy calculates the shannon entropy of b, in bits per byte.
+func byteEntropy(b []byte) float64 {
+	if len(b) == 0 {
+		return 0
+	}
+
+	var (
+		freq    [256]int
+		entropy float64
+		n       = float64(len(b))
+	)
+
+	for _, c := range b {
+		freq[c]++
+	}
+
+	for _, f := range freq {
+		if f == 0 {
+			continue
+		}
+
+		p := float64(f) / n
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
+
+// sniffText inspects the head of the reader to check that it looks
+// like text.  Returns false if the input should be skipped.  When
+// strict, anything that doesn't look like text is an error instead.
+func (h *handler) sniffText(
+	ctx context.Context,
+	filePath string,
+	br *bufio.Reader,
+) (bool, error) {
+	head, err := br.Peek(sniffSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, cluerr.WrapWC(ctx, err, "sniffing file")
+	}
+
+	if binary, controls := looksBinary(head); binary {
+		if h.strict {
+			return false, cluerr.NewWC(ctx, "file looks binary: "+filePath).
+				With("control_ratio", controls)
+		}
+
+		clog.Ctx(ctx).
+			With("file", filePath, "control_ratio", controls).
+			Info("skipping file with nul or control bytes; likely binary")
+
+		return false, nil
+	}
+
+	if len(head) < minEntropySample {
+		return true, nil
+	}
+
+	entropy := byteEntropy(head)
+	if entropy <= maxTextEntropy {
+		return true, nil
+	}
+
+	if h.strict {
+		return false, cluerr.NewWC(ctx, "file does not look like text: "+filePath).
+			With("entropy", entropy)
+	}
+
+	clog.Ctx(ctx).
+		With("file", filePath, "entropy", entropy).
+		Info("skipping file with high entropy; likely compressed or binary")
+
+	return false, nil
+}
+
+// looksBinary is true if head contains a nul byte, or too many control
+// bytes, to be text.  UTF-16 text is full of nuls, so it's never
+// considered binary.  Also returns the share of control bytes.
+func looksBinary(head []byte) (bool, float64) {
+	if len(head) == 0 {
+		return false, 0
+	}
+
+	switch detectEncoding(head, len(head) == sniffSize) {
+	case encUTF16LE, encUTF16BE:
+		return false, 0
+	}
+
+	var (
+		controls int
+		nul      bool
+	)
+
+	for _, c := range head {
+		switch {
+		case c == 0:
+			nul = true
+			controls++
+		case c == '\t', c == '\n', c == '\r', c == '\f', c == '\v':
+		case c < 0x20, c == 0x7F:
+			controls++
+		}
+	}
+
+	ratio := float64(controls) / float64(len(head))
+
+	return nul || ratio > maxControlRatio, ratio
+}
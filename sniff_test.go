@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+// randomBytes produces n bytes of high entropy data without any nul
+// or control bytes, the way text-looking compressed data might.
+func randomBytes(n int) []byte {
+	var (
+		rnd = rand.New(rand.NewPCG(1, 2))
+		bs  = make([]byte, n)
+	)
+
+	for i := range bs {
+		// 0x80-0xFF, and printable ascii.
+		bs[i] = byte(0x80 + rnd.IntN(0x80))
+		if rnd.IntN(2) == 0 {
+			bs[i] = byte(0x20 + rnd.IntN(0x5F))
+		}
+	}
+
+	return bs
+}
+
+func TestSniffTextEntropy(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		random = randomBytes(sniffSize)
+		text   = []byte(strings.Repeat("the quick brown fox jumps over the lazy dog\n", 100))
+	)
+
+	if e := byteEntropy(random); e <= maxTextEntropy {
+		t.Fatalf("random entropy = %.2f, want above %.2f", e, maxTextEntropy)
+	}
+
+	table := []struct {
+		name    string
+		input   []byte
+		strict  bool
+		want    bool
+		wantErr bool
+	}{
+		{"text", text, false, true, false},
+		{"random", random, false, false, false},
+		{"random, strict", random, true, false, true},
+		{"short random", random[:minEntropySample-1], false, true, false},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			h := newHandler()
+			h.strict = test.strict
+
+			isText, err := h.sniffText(ctx, "in.txt", bufio.NewReaderSize(bytes.NewReader(test.input), sniffSize))
+			if (err != nil) != test.wantErr {
+				t.Errorf("error = %v, want error %v", err, test.wantErr)
+			}
+
+			if isText != test.want {
+				t.Errorf("is text = %v, want %v", isText, test.want)
+			}
+		})
+	}
+}
+
+func TestCountSkipsHighEntropy(t *testing.T) {
+	dir := t.TempDir()
+
+	writeInput(t, dir, "text.txt", "the cat\n")
+	writeInput(t, dir, "random.txt", string(randomBytes(sniffSize)))
+
+	h, _ := runCount(t, dir)
+
+	if got := h.words.count.Value(); got != 2 {
+		t.Errorf("word count = %d, want only the 2 words of the text file", got)
+	}
+
+	if err := execCount(newHandler(), "--strict", dir); err == nil {
+		t.Error("expected an error for a high entropy file in strict mode")
+	}
+}
+
+func TestLooksBinary(t *testing.T) {
+	table := []struct {
+		name string
+		head string
+		want bool
+	}{
+		{"empty", "", false},
+		{"text", "plain text\twith tabs\r\nand lines\n", false},
+		{"nul", "mostly text\x00 but one nul", true},
+		{"controls", strings.Repeat("ab\x01\x02", 20), true},
+		{"few controls", strings.Repeat("a", 50) + "\x1b[0m", false},
+		{"utf-16", utf16("utf-16 text is full of nuls\n", false), false},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			if got, _ := looksBinary([]byte(test.head)); got != test.want {
+				t.Errorf("looks binary = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCountSkipsBinary(t *testing.T) {
+	dir := t.TempDir()
+
+	writeInput(t, dir, "text.txt", "the cat\n")
+	writeInput(t, dir, "stray.txt", "%PDF-1.4\n\x00\x01\x02 binary body\n")
+
+	h, _ := runCount(t, dir)
+
+	if got := h.words.count.Value(); got != 2 {
+		t.Errorf("word count = %d, want only the 2 words of the text file", got)
+	}
+
+	err := execCount(newHandler(), "--strict", dir)
+	if err == nil || !strings.Contains(err.Error(), "file looks binary") {
+		t.Errorf("error = %v, want a binary file error", err)
+	}
+}
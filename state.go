@@ -0,0 +1,159 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/alcionai/clues/cluerr"
+	"github.com/puzpuzpuz/xsync/v4"
+)
+
+// savedState is the complete set of counts from a run.  Saving
+// and later loading the state allows counts to be aggregated
+// across many separate runs.
+type savedState struct {
+	Words   statsState `json:"words"`
+	Letters statsState `json:"letters"`
+}
+
+type statsState struct {
+	Count        int64            `json:"count"`
+	Universal    map[string]int64 `json:"universal"`
+	CountSwapped int64            `json:"countSwapped"`
+	Swapped      map[string]int64 `json:"swapped"`
+	CountRemoved int64            `json:"countRemoved"`
+	Removed      map[string]int64 `json:"removed"`
+	CountBoth    int64            `json:"countBoth"`
+	Both         map[string]int64 `json:"both"`
+	CountChanged int64            `json:"countChanged,omitempty"`
+}
+
+func toStatsState(s stats) statsState {
+	return statsState{
+		Count:        s.count.Value(),
+		Universal:    toCountMap(s.universal),
+		CountSwapped: s.countSwapped.Value(),
+		Swapped:      toCountMap(s.swapped),
+		CountRemoved: s.countRemoved.Value(),
+		Removed:      toCountMap(s.removed),
+		CountBoth:    s.countBoth.Value(),
+		Both:         toCountMap(s.both),
+		CountChanged: s.countChanged.Value(),
+	}
+}
+
+func toCountMap(counter *xsync.Map[string, *xsync.Counter]) map[string]int64 {
+	m := map[string]int64{}
+
+	counter.Range(func(key string, value *xsync.Counter) bool {
+		m[key] = value.Value()
+		return true
+	})
+
+	return m
+}
+
+// addState adds all counts in the state onto the stats.
+func addState(s *stats, ss statsState) {
+	s.count.Add(ss.Count)
+	s.countSwapped.Add(ss.CountSwapped)
+	s.countRemoved.Add(ss.CountRemoved)
+	s.countBoth.Add(ss.CountBoth)
+	s.countChanged.Add(ss.CountChanged)
+
+	addCountMap(s.universal, ss.Universal)
+	addCountMap(s.swapped, ss.Swapped)
+	addCountMap(s.removed, ss.Removed)
+	addCountMap(s.both, ss.Both)
+}
+
+func addCountMap(
+	counter *xsync.Map[string, *xsync.Counter],
+	m map[string]int64,
+) {
+	for k, n := range m {
+		v, _ := counter.LoadOrCompute(k, func() (*xsync.Counter, bool) {
+			return xsync.NewCounter(), false
+		})
+
+		v.Add(n)
+	}
+}
+
+// saveState writes the handler's current counts to the file at path.
+// Paths ending in .gz are gzip compressed.
+func (h *handler) saveState(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return cluerr.Wrap(err, "creating state file")
+	}
+
+	defer f.Close()
+
+	var (
+		w  io.Writer = f
+		gz *gzip.Writer
+	)
+
+	if isGzip(path) {
+		gz = gzip.NewWriter(f)
+		w = gz
+	}
+
+	ss := savedState{
+		Words:   toStatsState(h.words),
+		Letters: toStatsState(h.letters),
+	}
+
+	if err := json.NewEncoder(w).Encode(ss); err != nil {
+		return cluerr.Wrap(err, "encoding state")
+	}
+
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return cluerr.Wrap(err, "compressing state")
+		}
+	}
+
+	return cluerr.Wrap(f.Close(), "closing state file").OrNil()
+}
+
+// readState reads a previously saved state from the file at path.
+// Compressed paths (ex: .gz) are decompressed.
+func readState(path string) (savedState, error) {
+	var ss savedState
+
+	f, err := os.Open(path)
+	if err != nil {
+		return ss, cluerr.Wrap(err, "opening state file")
+	}
+
+	r, err := decompress(path, f)
+	if err != nil {
+		return ss, cluerr.Wrap(err, "decompressing state")
+	}
+
+	defer r.Close()
+
+	if err := json.NewDecoder(r).Decode(&ss); err != nil {
+		return ss, cluerr.Wrap(err, "decoding state")
+	}
+
+	return ss, nil
+}
+
+// loadState adds the counts from a previously saved state onto
+// the handler's current counts.
+func (h *handler) loadState(path string) error {
+	ss, err := readState(path)
+	if err != nil {
+		return err
+	}
+
+	addState(&h.words, ss.Words)
+	addState(&h.letters, ss.Letters)
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	for _, name := range []string{"state.json", "state.json.gz"} {
+		t.Run(name, func(t *testing.T) {
+			var (
+				path  = filepath.Join(t.TempDir(), name)
+				saved = newHandler()
+			)
+
+			saved.swapNGrams = []nGramSwap{{from: "th", to: "ð"}}
+			saved.removeWords["cat"] = struct{}{}
+			saved.processLine(context.Background(), []string{"the", "cat", "the", "then"})
+
+			if err := saved.saveState(path); err != nil {
+				t.Fatalf("saving state: %v", err)
+			}
+
+			bs, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("reading state: %v", err)
+			}
+
+			if gzipped := bytes.HasPrefix(bs, []byte{0x1f, 0x8b}); gzipped != isGzip(path) {
+				t.Errorf("state gzipped = %v, want %v", gzipped, isGzip(path))
+			}
+
+			loaded := newHandler()
+
+			if err := loaded.loadState(path); err != nil {
+				t.Fatalf("loading state: %v", err)
+			}
+
+			for _, s := range []struct {
+				name          string
+				saved, loaded stats
+			}{
+				{"words", saved.words, loaded.words},
+				{"letters", saved.letters, loaded.letters},
+			} {
+				want, got := toStatsState(s.saved), toStatsState(s.loaded)
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("loaded %s = %+v, want %+v", s.name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadStateAddsCounts(t *testing.T) {
+	var (
+		dir   = t.TempDir()
+		path  = filepath.Join(dir, "state.json.gz")
+		input = writeInput(t, dir, "in.txt", "the cat the\n")
+	)
+
+	runCount(t, "--save-state="+path, input)
+
+	h, _ := runCount(t, "--load-state="+path, input)
+
+	if got := countOf(h.words.universal, "the"); got != 4 {
+		t.Errorf("count of the = %d, want 4", got)
+	}
+
+	if got := h.words.count.Value(); got != 6 {
+		t.Errorf("word count = %d, want 6", got)
+	}
+}
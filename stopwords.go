@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/alcionai/clues/cluerr"
+)
+
+// stopwordLists holds a stopword list for each supported language,
+// named by its iso 639-1 code (ex: stopwords/en.txt).  Each list holds
+// one word per line.
+//
+//go:embed stopwords/*.txt
+var stopwordLists embed.FS
+
+// stopwordLanguages produces the codes of every embedded list.
+func stopwordLanguages() []string {
+	entries, _ := fs.ReadDir(stopwordLists, "stopwords")
+	langs := make([]string, 0, len(entries))
+
+	for _, e := range entries {
+		langs = append(langs, strings.TrimSuffix(e.Name(), path.Ext(e.Name())))
+	}
+
+	slices.Sort(langs)
+
+	return langs
+}
+
+// readStopwords produces the words in the language's embedded list.
+func readStopwords(lang string) ([]string, error) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+
+	bs, err := stopwordLists.ReadFile("stopwords/" + lang + ".txt")
+	if err != nil {
+		return nil, cluerr.New("no stopwords for language: "+lang).
+			With("languages", stopwordLanguages())
+	}
+
+	var (
+		words   = []string{}
+		scanner = bufio.NewScanner(bytes.NewReader(bs))
+	)
+
+	for scanner.Scan() {
+		if word := strings.TrimSpace(scanner.Text()); len(word) > 0 {
+			words = append(words, word)
+		}
+	}
+
+	return words, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestStopwordLanguages(t *testing.T) {
+	if langs := stopwordLanguages(); !slices.Equal(langs, []string{"de", "en", "es", "fr"}) {
+		t.Errorf("stopword languages = %q", langs)
+	}
+}
+
+func TestReadStopwords(t *testing.T) {
+	for _, lang := range stopwordLanguages() {
+		words, err := readStopwords(lang)
+		if err != nil {
+			t.Errorf("reading %s stopwords: %v", lang, err)
+			continue
+		}
+
+		if len(words) == 0 {
+			t.Errorf("no %s stopwords", lang)
+		}
+
+		if slices.Contains(words, "") {
+			t.Errorf("%s stopwords contain a blank word", lang)
+		}
+	}
+
+	words, err := readStopwords(" EN ")
+	if err != nil {
+		t.Fatalf("reading EN stopwords: %v", err)
+	}
+
+	if !slices.Contains(words, "the") {
+		t.Error("expected the en stopwords to contain the")
+	}
+
+	_, err = readStopwords("xx")
+	if err == nil || !strings.Contains(err.Error(), "no stopwords for language: xx") {
+		t.Errorf("expected an unknown language error, got %v", err)
+	}
+}
+
+func TestStopwordsInput(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "the cat and le chat sat\n")
+
+	h, _ := runCount(t, "--stopwords=en,fr", "-r=sat", input)
+
+	for word, want := range map[string]int64{"the": 0, "and": 0, "le": 0, "sat": 0, "cat": 1, "chat": 1} {
+		if got := countOf(h.words.removed, word); got != want {
+			t.Errorf("count of %q after removal = %d, want %d", word, got, want)
+		}
+	}
+
+	if got := h.words.countRemoved.Value(); got != 4 {
+		t.Errorf("removed word count = %d, want 4", got)
+	}
+
+	err := execCount(newHandler(), "--stopwords=xx", input)
+	if err == nil || !strings.Contains(err.Error(), "no stopwords for language") {
+		t.Errorf("expected an unknown language error, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// subtitleExts holds the extensions of subtitle files, whose cues
+// get counted without their numbering and timings.
+var subtitleExts = []string{".srt", ".vtt"}
+
+// subtitleTagRE matches both html-ish tags (ex: <i>, <v Speaker>)
+// and ass-style overrides (ex: {\an8}).
+var subtitleTagRE = regexp.MustCompile(`<[^>]*>|\{\\[^}]*\}`)
+
+// isSubtitle is true if the path is an srt or vtt file.  Subtitles can
+// also be compressed (ex: .srt.gz).
+func isSubtitle(p string) bool {
+	p = strings.ToLower(trimCompressedExt(p))
+
+	for _, ext := range subtitleExts {
+		if strings.HasSuffix(p, ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// subtitleState tracks whether a line falls within the text of a
+// subtitle cue.  Cues are separated by blank lines, and their text
+// follows a timing line (ex: 00:00:01,000 --> 00:00:02,000).
+// Anything else, such as srt sequence numbers, vtt cue identifiers,
+// headers, and notes, never follows a timing line.
+type subtitleState struct {
+	inCue bool
+}
+
+// next advances the state past the line, and produces the line's cue
+// text, with all formatting tags removed.  The result is false if
+// the line isn't cue text.
+func (ss *subtitleState) next(ln string) (string, bool) {
+	switch {
+	case len(strings.TrimSpace(ln)) == 0:
+		ss.inCue = false
+		return ln, false
+
+	// timings are followed by cue settings (ex: align:start), which
+	// get dropped along with them.
+	case strings.Contains(ln, "-->"):
+		ss.inCue = true
+		return ln, false
+
+	case ss.inCue:
+		return subtitleTagRE.ReplaceAllString(ln, " "), true
+	}
+
+	return ln, false
+}
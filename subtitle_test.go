@@ -0,0 +1,89 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testSRT = `1
+00:00:01,000 --> 00:00:02,500
+<i>Hello</i> there.
+
+2
+00:00:03,000 --> 00:00:04,000
+{\an8}Two lines
+of dialogue.
+`
+
+const testVTT = `WEBVTT
+Kind: captions
+
+NOTE a note about the file
+
+intro
+00:00:01.000 --> 00:00:02.000 align:start position:10%
+<v Narrator>Spoken words</v>
+
+00:00:03.000 --> 00:00:04.000
+<c.yellow>more</c> words
+`
+
+func TestSubtitleState(t *testing.T) {
+	table := []struct {
+		name, text string
+		want       []string
+	}{
+		{"srt", testSRT, []string{"Hello there.", "Two lines", "of dialogue."}},
+		{"vtt", testVTT, []string{"Spoken words", "more words"}},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				ss   subtitleState
+				cues = []string{}
+			)
+
+			for _, ln := range strings.Split(test.text, "\n") {
+				if text, ok := ss.next(ln); ok {
+					cues = append(cues, strings.Join(strings.Fields(text), " "))
+				}
+			}
+
+			if !slices.Equal(cues, test.want) {
+				t.Errorf("cues = %q, want %q", cues, test.want)
+			}
+		})
+	}
+}
+
+func TestCountSubtitles(t *testing.T) {
+	dir := t.TempDir()
+
+	writeInput(t, dir, "a.srt", testSRT)
+	writeInput(t, dir, "b.vtt", testVTT)
+
+	h, _ := runCount(t, filepath.Join(dir, "a.srt"), filepath.Join(dir, "b.vtt"))
+
+	for word, want := range map[string]int64{
+		"hello":    1,
+		"words":    2,
+		"dialogue": 1,
+		"narrator": 0,
+		"webvtt":   0,
+		"note":     0,
+		"align":    0,
+		"00":       0,
+		"1":        0,
+	} {
+		if got := countOf(h.words.universal, word); got != want {
+			t.Errorf("count of %s = %d, want %d", word, got, want)
+		}
+	}
+
+	if got := countOf(h.letters.universal, "0"); got != 0 {
+		t.Errorf("count of the letter 0 = %d, want 0", got)
+	}
+}
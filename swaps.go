@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/alcionai/clues/cluerr"
+	"github.com/rivo/uniseg"
+)
+
+// swapCommentRE matches a comment: a # at the start of the line, or
+// after whitespace, through the end of the line.
+var swapCommentRE = regexp.MustCompile(`(^|\s)#.*$`)
+
+// groupRefRE matches the group references of a replacement template
+// (ex: $1, $name, ${name}), and its escaped $$.
+var groupRefRE = regexp.MustCompile(`\$(\$|\{[^}]*\}|[0-9A-Za-z_]+)`)
+
+// parseSwap parses a single from,to swap.  A from that starts with
+// ^ only matches at the start of a word, and one that ends with $
+// only matches at the end of a word (ex: ^kn,n or ough$,o).
+func parseSwap(swap string) (nGramSwap, error) {
+	parts := strings.Split(
+		strings.ToLower(swap),
+		",",
+	)
+
+	if len(parts) != 2 {
+		return nGramSwap{}, cluerr.New("improperly formed swapNGram: only one ',' expected").
+			With("input", swap)
+	}
+
+	from, to := parts[0], parts[1]
+
+	if strings.HasPrefix(from, "^") || strings.HasSuffix(from, "$") {
+		return anchoredSwap(from, to), nil
+	}
+
+	return nGramSwap{
+		from: from,
+		to:   to,
+	}, nil
+}
+
+// anchoredSwap produces a swap of the literal from, held to the start
+// and/or end of the word by its ^ and $ anchors.
+func anchoredSwap(from, to string) nGramSwap {
+	var (
+		body   = strings.TrimSuffix(strings.TrimPrefix(from, "^"), "$")
+		prefix = strings.HasPrefix(from, "^")
+		suffix = strings.HasSuffix(from, "$")
+		expr   = regexp.QuoteMeta(body)
+	)
+
+	if prefix {
+		expr = "^" + expr
+	}
+
+	if suffix {
+		expr = expr + "$"
+	}
+
+	return nGramSwap{
+		from: from,
+		// the replacement is literal, so any $ can't refer to a group.
+		to: strings.ReplaceAll(to, "$", "$$"),
+		re: regexp.MustCompile(expr),
+	}
+}
+
+// parseRegexSwap parses a single regex,replacement swap.  Regexes
+// often contain commas (ex: {1,3}), so the pair is split at the last
+// comma.
+func parseRegexSwap(swap string) (nGramSwap, error) {
+	i := strings.LastIndex(swap, ",")
+	if i < 0 {
+		return nGramSwap{}, cluerr.New("improperly formed swapRegex: a ',' is expected").
+			With("input", swap)
+	}
+
+	from, to := swap[:i], lowerLiterals(swap[i+1:])
+
+	re, err := regexp.Compile(from)
+	if err != nil {
+		return nGramSwap{}, cluerr.Wrap(err, "compiling swapRegex").
+			With("input", swap)
+	}
+
+	return nGramSwap{
+		from: from,
+		to:   to,
+		re:   re,
+	}, nil
+}
+
+// lowerLiterals lowercases the literal text of the replacement
+// template, leaving its group references intact, since group names
+// are case sensitive (ex: ${Vow}M becomes ${Vow}m).
+func lowerLiterals(tmpl string) string {
+	var (
+		sb   strings.Builder
+		last int
+	)
+
+	for _, m := range groupRefRE.FindAllStringIndex(tmpl, -1) {
+		sb.WriteString(strings.ToLower(tmpl[last:m[0]]))
+		sb.WriteString(tmpl[m[0]:m[1]])
+
+		last = m[1]
+	}
+
+	sb.WriteString(strings.ToLower(tmpl[last:]))
+
+	return sb.String()
+}
+
+// wholeWord produces a copy of the swap that only rewrites words
+// that entirely match it.
+func (s nGramSwap) wholeWord() nGramSwap {
+	s.whole = true
+
+	if s.re != nil {
+		// the regex already compiled, so anchoring it can't fail.
+		s.re = regexp.MustCompile(`^(?:` + s.re.String() + `)$`)
+	}
+
+	return s
+}
+
+// matchingCase produces a copy of the swap that matches without
+// regard to case, and recases its replacement to match the casing
+// of the text it replaces (ex: with th,ð, Th becomes Ð).
+func (s nGramSwap) matchingCase() nGramSwap {
+	s.preserveCase = true
+
+	if s.re != nil {
+		// the regex already compiled, so it can't fail with a flag.
+		s.re = regexp.MustCompile(`(?i)` + s.re.String())
+		return s
+	}
+
+	s.re = regexp.MustCompile(`(?i)` + regexp.QuoteMeta(s.from))
+	// the replacement is literal, so any $ can't refer to a group.
+	s.to = strings.ReplaceAll(s.to, "$", "$$")
+
+	return s
+}
+
+// replaceMatchingCase replaces every match of the swap's regex within
+// the word, recasing each replacement to match the text it replaces.
+func (s nGramSwap) replaceMatchingCase(word string) (string, int) {
+	var (
+		sb   strings.Builder
+		last int
+		n    int
+	)
+
+	for _, m := range s.re.FindAllStringSubmatchIndex(word, -1) {
+		matched := word[m[0]:m[1]]
+		to := string(s.re.ExpandString(nil, s.to, word, m))
+
+		sb.WriteString(word[last:m[0]])
+		sb.WriteString(matchCase(to, matched))
+
+		n += uniseg.GraphemeClusterCount(matched)
+		last = m[1]
+	}
+
+	sb.WriteString(word[last:])
+
+	return sb.String(), n
+}
+
+// matchCase recases to after the casing of matched.  Matches of two
+// or more letters that are all uppercase (ex: TH) produce uppercase,
+// and matches that only start uppercase (ex: Th) produce a leading
+// uppercase letter.  Otherwise, to is unchanged.
+func matchCase(to, matched string) string {
+	var letters, upper int
+
+	for _, r := range matched {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+
+		letters++
+
+		if unicode.IsUpper(r) {
+			upper++
+		}
+	}
+
+	first, size := utf8.DecodeRuneInString(matched)
+
+	switch {
+	case letters > 1 && upper == letters:
+		return strings.ToUpper(to)
+	case size > 0 && unicode.IsUpper(first):
+		r, size := utf8.DecodeRuneInString(to)
+		return string(unicode.ToUpper(r)) + to[size:]
+	}
+
+	return to
+}
+
+// apply produces the word with every occurrence of the swap replaced,
+// and the count of letters that were replaced.
+func (s nGramSwap) apply(word string) (string, int) {
+	if s.re != nil && s.preserveCase {
+		return s.replaceMatchingCase(word)
+	}
+
+	if s.re != nil {
+		var n int
+
+		for _, m := range s.re.FindAllStringIndex(word, -1) {
+			n += uniseg.GraphemeClusterCount(word[m[0]:m[1]])
+		}
+
+		return s.re.ReplaceAllString(word, s.to), n
+	}
+
+	if s.whole {
+		if word != s.from {
+			return word, 0
+		}
+
+		return s.to, uniseg.GraphemeClusterCount(word)
+	}
+
+	n := strings.Count(word, s.from) * uniseg.GraphemeClusterCount(s.from)
+
+	return strings.ReplaceAll(word, s.from, s.to), n
+}
+
+// readSwapFile reads one from,to swap per line from the file at path,
+// in order.  Anything after a # is a comment, and blank lines are
+// skipped.
+func readSwapFile(path string) ([]nGramSwap, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, cluerr.Wrap(err, "opening swap file")
+	}
+
+	defer f.Close()
+
+	var (
+		swaps   = []nGramSwap{}
+		scanner = bufio.NewScanner(f)
+	)
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		ln := strings.TrimSpace(swapCommentRE.ReplaceAllString(scanner.Text(), ""))
+		if len(ln) == 0 {
+			continue
+		}
+
+		swap, err := parseSwap(ln)
+		if err != nil {
+			return nil, cluerr.Wrap(err, "parsing swap file").
+				With("line", lineNo)
+		}
+
+		swaps = append(swaps, swap)
+	}
+
+	return swaps, cluerr.Wrap(scanner.Err(), "reading swap file").OrNil()
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRegexSwap(t *testing.T) {
+	table := []struct {
+		name, swap, word, want string
+		wantChanged            int
+	}{
+		{"numbered group", `c([ei]),s$1`, "cinder", "sinder", 2},
+		{"braced numbered group", `(a)n,${1}M`, "banana", "bamama", 4},
+		{"named group", `(?P<Vow>[aeiou])n,${Vow}m`, "banana", "bamama", 4},
+		{"escaped dollar", `a,$$`, "ab", "$b", 1},
+		{"lowercased literal", `(a)b,${1}X`, "ab", "ax", 2},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			swap, err := parseRegexSwap(test.swap)
+			if err != nil {
+				t.Fatalf("parsing %q: %v", test.swap, err)
+			}
+
+			got, changed := swap.apply(test.word)
+			if got != test.want {
+				t.Errorf("%q swapped to %q, want %q", test.word, got, test.want)
+			}
+
+			if changed != test.wantChanged {
+				t.Errorf("changed letters = %d, want %d", changed, test.wantChanged)
+			}
+		})
+	}
+}
+
+func TestSwapRegexNamedGroup(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "banana\n")
+
+	h, _ := runCount(t, "--swapRegex=(?P<Vow>[aeiou])n,${Vow}m", input)
+
+	if got := countOf(h.words.swapped, "bamama"); got != 1 {
+		t.Errorf("count of bamama in swapped = %d, want 1", got)
+	}
+}
+
+func TestMatchingCaseSwap(t *testing.T) {
+	table := []struct {
+		name, swap, word, want string
+	}{
+		{"all caps", "th,ð", "THE", "ÐE"},
+		{"title case", "th,ð", "The", "Ðe"},
+		{"lower case", "th,ð", "the", "ðe"},
+		{"mixed case", "th,ð", "tHe", "ðe"},
+		{"longer all caps", "th,thh", "THE", "THHE"},
+		{"longer title case", "th,thh", "The", "Thhe"},
+		{"longer single letter", "e,ea", "E", "Ea"},
+		{"every match", "th,ð", "TH th Th", "Ð ð Ð"},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			swap, err := parseSwap(test.swap)
+			if err != nil {
+				t.Fatalf("parsing %q: %v", test.swap, err)
+			}
+
+			got, _ := swap.matchingCase().apply(test.word)
+			if got != test.want {
+				t.Errorf("%q swapped to %q, want %q", test.word, got, test.want)
+			}
+		})
+	}
+}
+
+func TestMatchCase(t *testing.T) {
+	table := []struct {
+		to, matched, want string
+	}{
+		{"ð", "TH", "Ð"},
+		{"ð", "Th", "Ð"},
+		{"ð", "th", "ð"},
+		{"ea", "E", "Ea"},
+		{"ea", "e", "ea"},
+		{"ð", "'T", "ð"},
+		{"", "TH", ""},
+	}
+
+	for _, test := range table {
+		if got := matchCase(test.to, test.matched); got != test.want {
+			t.Errorf("matchCase(%q, %q) = %q, want %q", test.to, test.matched, got, test.want)
+		}
+	}
+}
+
+func TestCaseSensitiveSwaps(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "The THE the\n")
+
+	h, _ := runCount(t, "--case-sensitive", "-s=th,ð", input)
+
+	for _, word := range []string{"Ðe", "ÐE", "ðe"} {
+		if got := countOf(h.words.swapped, word); got != 1 {
+			t.Errorf("count of %s in swapped = %d, want 1", word, got)
+		}
+	}
+}
+
+func TestAnchoredSwap(t *testing.T) {
+	table := []struct {
+		name, swap, word, want string
+		wantChanged            int
+	}{
+		{"start", "^kn,n", "knickknack", "nickknack", 2},
+		{"start, unmatched", "^kn,n", "acknowledge", "acknowledge", 0},
+		{"end", "ough$,o", "through", "thro", 4},
+		{"end, unmatched", "ough$,o", "toughest", "toughest", 0},
+		{"whole word", "^a$,æ", "a", "æ", 1},
+		{"whole word, unmatched", "^a$,æ", "aa", "aa", 0},
+		{"literal caret", "a^b,x", "a^bc", "xc", 3},
+		{"literal dollar", "a$b,x", "ca$b", "cx", 3},
+		{"anchored literal dollar", "^a$b,x", "a$ba$b", "xa$b", 3},
+		{"literal dollar replacement", "^a,$1", "ab", "$1b", 1},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			swap, err := parseSwap(test.swap)
+			if err != nil {
+				t.Fatalf("parsing %q: %v", test.swap, err)
+			}
+
+			got, changed := swap.apply(test.word)
+			if got != test.want {
+				t.Errorf("%q swapped to %q, want %q", test.word, got, test.want)
+			}
+
+			if changed != test.wantChanged {
+				t.Errorf("changed letters = %d, want %d", changed, test.wantChanged)
+			}
+		})
+	}
+}
+
+func TestSwapWholeWords(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "a banana and a pear\n")
+
+	h, _ := runCount(t, "--swapWholeWords", "-s=a,æ", input)
+
+	table := []struct {
+		word string
+		want int64
+	}{
+		{"æ", 2},
+		{"banana", 1},
+		{"and", 1},
+		{"pear", 1},
+		{"a", 0},
+		{"bænænæ", 0},
+	}
+
+	for _, test := range table {
+		if got := countOf(h.words.swapped, test.word); got != test.want {
+			t.Errorf("count of %s in swapped = %d, want %d", test.word, got, test.want)
+		}
+	}
+
+	if got := h.letters.countChanged.Value(); got != 2 {
+		t.Errorf("changed letters = %d, want 2", got)
+	}
+}
+
+func TestWholeWordRegexSwap(t *testing.T) {
+	swap, err := parseRegexSwap("a+,o")
+	if err != nil {
+		t.Fatalf("parsing swap: %v", err)
+	}
+
+	swap = swap.wholeWord()
+
+	for word, want := range map[string]string{"aa": "o", "banana": "banana"} {
+		if got, _ := swap.apply(word); got != want {
+			t.Errorf("%q swapped to %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestReadSwapFile(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		path = writeInput(t, dir, "rules.txt", "# digraphs\nth,ð # for the\n\n  SH,ʃ\nc#,sharp\n^kn,n\n")
+	)
+
+	swaps, err := readSwapFile(path)
+	if err != nil {
+		t.Fatalf("reading swap file: %v", err)
+	}
+
+	if len(swaps) != 4 {
+		t.Fatalf("read %d swaps, want 4: %+v", len(swaps), swaps)
+	}
+
+	table := []struct {
+		word, want string
+	}{
+		{"the", "ðe"},
+		{"ship", "ʃip"},
+		{"c#", "sharp"},
+		{"knack", "nack"},
+	}
+
+	for i, test := range table {
+		if got, _ := swaps[i].apply(test.word); got != test.want {
+			t.Errorf("swap %d: %q swapped to %q, want %q", i, test.word, got, test.want)
+		}
+	}
+
+	bad := writeInput(t, dir, "bad.txt", "th,ð\n\nth,ð,d\n")
+
+	_, err = readSwapFile(bad)
+	if err == nil || !strings.Contains(err.Error(), "only one ',' expected") {
+		t.Errorf("expected a malformed swap error, got %v", err)
+	}
+
+	if _, err := readSwapFile(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Error("expected an error for a missing swap file")
+	}
+}
+
+func TestSwapFileInput(t *testing.T) {
+	var (
+		dir   = t.TempDir()
+		rules = writeInput(t, dir, "rules.txt", "ð,d\n")
+		input = writeInput(t, dir, "in.txt", "the\n")
+	)
+
+	// file swaps follow the -s swaps.
+	h, _ := runCount(t, "-s=th,ð", "--swapFile="+rules, input)
+
+	if got := countOf(h.words.swapped, "de"); got != 1 {
+		t.Errorf("count of de in swapped = %d, want 1", got)
+	}
+
+	err := execCount(newHandler(), "--swapFile="+filepath.Join(dir, "missing.txt"), input)
+	if err == nil || !strings.Contains(err.Error(), "loading swaps") {
+		t.Errorf("expected a loading error, got %v", err)
+	}
+}
+
+func TestCumulativeSwaps(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "that then\n")
+
+	table := []struct {
+		name  string
+		flags []string
+		want  map[string]int64
+	}{
+		{
+			"later swaps rewrite earlier ones",
+			[]string{"-s=th,ð", "-s=ða,đa"},
+			map[string]int64{"đat": 1, "ðen": 1, "ðat": 0},
+		},
+		{
+			"in the order given",
+			[]string{"-s=ða,đa", "-s=th,ð"},
+			map[string]int64{"đat": 0, "ðat": 1, "ðen": 1},
+		},
+		{
+			"regex swaps follow ngram swaps",
+			[]string{"--swapRegex=ð([aeiou]),đ$1", "-s=th,ð"},
+			map[string]int64{"đat": 1, "đen": 1},
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			h, _ := runCount(t, append(test.flags, input)...)
+
+			for word, want := range test.want {
+				if got := countOf(h.words.swapped, word); got != want {
+					t.Errorf("count of %q in swapped = %d, want %d", word, got, want)
+				}
+			}
+		})
+	}
+
+	// both swaps rewrite the same letters, and no more letters can
+	// change than the word holds.
+	h, _ := runCount(t, "-s=th,ð", "-s=ð,d", writeInput(t, t.TempDir(), "in.txt", "th\n"))
+
+	if got := h.letters.countChanged.Value(); got != 2 {
+		t.Errorf("changed letter count = %d, want 2", got)
+	}
+}
+
+func TestGrowingSwaps(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "bee tree\n")
+
+	h, report := runCount(t, "-s=e,ea", "--swap-ratio", input)
+
+	if got := h.letters.count.Value(); got != 7 {
+		t.Errorf("raw letter count = %d, want 7", got)
+	}
+
+	if got := h.letters.countSwapped.Value(); got != 11 {
+		t.Errorf("swapped letter count = %d, want 11", got)
+	}
+
+	for letter, want := range map[string]int64{"e": 4, "a": 0} {
+		if got := countOf(h.letters.universal, letter); got != want {
+			t.Errorf("raw count of %q = %d, want %d", letter, got, want)
+		}
+	}
+
+	for letter, want := range map[string]int64{"e": 4, "a": 4} {
+		if got := countOf(h.letters.swapped, letter); got != want {
+			t.Errorf("swapped count of %q = %d, want %d", letter, got, want)
+		}
+	}
+
+	// each column's percentages are of its own total.
+	for _, want := range []string{
+		"| raw (7) | removed (7) | swapped (11) | both (11) |",
+		"|     e (     4, 57.14%) |     e (     4, 57.14%) |     a (     4, 36.36%) |",
+		"letters changed by swaps: 4 of 7 (57.14%)",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q:\n%s", want, report)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+//go:build windows || plan9
+
+package main
+
+// log/syslog isn't available on windows or plan9.
+const syslogSupported = false
@@ -0,0 +1,36 @@
+//go:build !windows && !plan9
+
+package main
+
+import (
+	"log/syslog"
+	"net/url"
+
+	"go.uber.org/zap"
+)
+
+const syslogSupported = true
+
+func init() {
+	err := zap.RegisterSink("syslog", func(*url.URL) (zap.Sink, error) {
+		w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_USER, "count")
+		if err != nil {
+			return nil, err
+		}
+
+		return syslogSink{w}, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// syslogSink adapts the syslog writer into a zap sink.
+type syslogSink struct {
+	*syslog.Writer
+}
+
+// Sync is a no-op, since syslog writes are never buffered.
+func (s syslogSink) Sync() error {
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/alcionai/clues/cluerr"
+)
+
+// tableDelimiters maps the extensions of delimited tables to the
+// delimiter between their fields.
+var tableDelimiters = map[string]rune{
+	".csv": ',',
+	".tsv": '\t',
+}
+
+// tableDelimiter produces the delimiter of the table at the path,
+// and false if the path isn't a table.  Tables can also be
+// compressed (ex: .csv.gz).
+func tableDelimiter(p string) (rune, bool) {
+	p = strings.ToLower(trimCompressedExt(p))
+
+	for ext, delim := range tableDelimiters {
+		if strings.HasSuffix(p, ext) {
+			return delim, true
+		}
+	}
+
+	return 0, false
+}
+
+// tableColumn selects the column of a table that gets counted, either
+// by its name in the header row, or by its 0-based index.
+type tableColumn struct {
+	name  string
+	index int
+}
+
+// parseTableColumn treats any non-negative integer as an index, and
+// anything else as a column name.
+func parseTableColumn(s string) (tableColumn, error) {
+	s = strings.TrimSpace(s)
+
+	if len(s) == 0 {
+		return tableColumn{}, cluerr.New("csv-column cannot be empty")
+	}
+
+	if i, err := strconv.Atoi(s); err == nil {
+		if i < 0 {
+			return tableColumn{}, cluerr.New("csv-column index cannot be negative").
+				With("input", s)
+		}
+
+		return tableColumn{index: i}, nil
+	}
+
+	return tableColumn{name: s, index: -1}, nil
+}
+
+// tableText produces the values of the column in each row of the
+// table, one per line.  Selecting the column by name consumes the
+// header row, while selecting by index counts every row.  Rows that
+// are too short to have the column are skipped.  Closing the result
+// stops the parsing.
+func tableText(r io.Reader, delim rune, col tableColumn) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		pw.CloseWithError(writeTableText(r, delim, col, pw))
+	}()
+
+	return pr
+}
+
+func writeTableText(
+	r io.Reader,
+	delim rune,
+	col tableColumn,
+	w io.Writer,
+) error {
+	cr := csv.NewReader(r)
+	cr.Comma = delim
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.ReuseRecord = true
+
+	idx := col.index
+
+	if len(col.name) > 0 {
+		header, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return cluerr.Wrap(err, "reading table header")
+		}
+
+		idx = slices.IndexFunc(header, func(name string) bool {
+			return strings.TrimSpace(name) == col.name
+		})
+
+		if idx < 0 {
+			return cluerr.New("table has no column: "+col.name).
+				With("columns", slices.Clone(header))
+		}
+	}
+
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return cluerr.Wrap(err, "reading table row")
+		}
+
+		if idx >= len(rec) {
+			continue
+		}
+
+		if _, err := io.WriteString(w, rec[idx]+"\n"); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+const testTable = `id,review_text,stars
+1,"short and sweet",5
+2,"spans
+two lines, with a comma",3
+3
+4,"says ""quoted"" words",4
+`
+
+func TestTableText(t *testing.T) {
+	table := []struct {
+		name  string
+		text  string
+		delim rune
+		col   string
+		want  string
+	}{
+		{
+			name:  "by name",
+			text:  testTable,
+			delim: ',',
+			col:   "review_text",
+			want:  "short and sweet\nspans\ntwo lines, with a comma\nsays \"quoted\" words\n",
+		},
+		{
+			name:  "by index",
+			text:  testTable,
+			delim: ',',
+			col:   "2",
+			want:  "stars\n5\n3\n4\n",
+		},
+		{
+			name:  "tsv",
+			text:  "name\ttext\nmine\tsome words\n",
+			delim: '\t',
+			col:   "text",
+			want:  "some words\n",
+		},
+		{
+			name:  "padded header",
+			text:  "id, text \n1,padded\n",
+			delim: ',',
+			col:   "text",
+			want:  "padded\n",
+		},
+		{
+			name:  "empty",
+			delim: ',',
+			col:   "text",
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			col, err := parseTableColumn(test.col)
+			if err != nil {
+				t.Fatalf("parsing column: %v", err)
+			}
+
+			text := tableText(strings.NewReader(test.text), test.delim, col)
+			defer text.Close()
+
+			got, err := io.ReadAll(text)
+			if err != nil {
+				t.Fatalf("reading column: %v", err)
+			}
+
+			if string(got) != test.want {
+				t.Errorf("column text = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTableTextMissingColumn(t *testing.T) {
+	text := tableText(strings.NewReader(testTable), ',', tableColumn{name: "title", index: -1})
+	defer text.Close()
+
+	_, err := io.ReadAll(text)
+	if err == nil || !strings.Contains(err.Error(), "table has no column: title") {
+		t.Errorf("error = %v, want a missing column error", err)
+	}
+}
+
+func TestParseTableColumnErrors(t *testing.T) {
+	for _, s := range []string{"", "  ", "-1"} {
+		if _, err := parseTableColumn(s); err == nil {
+			t.Errorf("parseTableColumn(%q) should error", s)
+		}
+	}
+}
+
+func TestCSVColumnInput(t *testing.T) {
+	dir := t.TempDir()
+	input := writeInput(t, dir, "reviews.csv", testTable)
+
+	h, _ := runCount(t, "--csv-column=review_text", input)
+
+	for word, want := range map[string]int64{
+		"spans":  1,
+		"lines":  1,
+		"quoted": 1,
+		"stars":  0,
+		"id":     0,
+	} {
+		if got := countOf(h.words.universal, word); got != want {
+			t.Errorf("count of %s = %d, want %d", word, got, want)
+		}
+	}
+
+	err := execCount(newHandler(), "--csv-column=title", input)
+	if err == nil || !strings.Contains(err.Error(), "table has no column: title") {
+		t.Errorf("error = %v, want a missing column error", err)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// tokenizer splits lines of text into the words that get counted.
+// Alternative tokenizers can be added to tokenizers without touching
+// the scanning or counting of lines.
+type tokenizer interface {
+	// tokenize produces the words of the line, whether the line ended
+	// in a dash-broken word, and the count of non-whitespace characters
+	// that were dropped from the line.
+	tokenize(ln string) ([]string, bool, int)
+}
+
+// tokenizers maps the name of each tokenizer to its constructor.
+var tokenizers = map[string]func(opts normalizeOpts) tokenizer{
+	"regex": func(opts normalizeOpts) tokenizer {
+		return regexTokenizer{opts: opts}
+	},
+	"whitespace": func(normalizeOpts) tokenizer {
+		return whitespaceTokenizer{}
+	},
+}
+
+const defaultTokenizer = "regex"
+
+func tokenizerNames() []string {
+	return slices.Sorted(maps.Keys(tokenizers))
+}
+
+// regexTokenizer lowercases lines, and strips them down to the
+// characters kept by the normalization options.  It's the default.
+type regexTokenizer struct {
+	opts normalizeOpts
+}
+
+func (t regexTokenizer) tokenize(ln string) ([]string, bool, int) {
+	return normalize(ln, t.opts)
+}
+
+// whitespaceTokenizer splits lines on whitespace, and nothing else:
+// case, punctuation, and symbols are all kept as they are, and none
+// of the normalization options apply.
+type whitespaceTokenizer struct{}
+
+func (whitespaceTokenizer) tokenize(ln string) ([]string, bool, int) {
+	return strings.Fields(ln), false, 0
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTokenizers(t *testing.T) {
+	const ln = "Don't STOP, the-end"
+
+	table := []struct {
+		name string
+		want []string
+	}{
+		{"regex", []string{"dont", "stop", "theend"}},
+		{"whitespace", []string{"Don't", "STOP,", "the-end"}},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			opts := normalizeOpts{hyphens: hyphensJoin}
+			opts.compile()
+
+			words, _, _ := tokenizers[test.name](opts).tokenize(ln)
+
+			if !slices.Equal(words, test.want) {
+				t.Errorf("words = %q, want %q", words, test.want)
+			}
+		})
+	}
+
+	if names := tokenizerNames(); !slices.Equal(names, []string{"regex", "whitespace"}) {
+		t.Errorf("tokenizer names = %q", names)
+	}
+}
+
+// reverseTokenizer produces words spelled backwards.
+type reverseTokenizer struct{}
+
+func (reverseTokenizer) tokenize(ln string) ([]string, bool, int) {
+	words := strings.Fields(ln)
+
+	for i, word := range words {
+		runes := []rune(word)
+		slices.Reverse(runes)
+		words[i] = string(runes)
+	}
+
+	return words, false, 0
+}
+
+func TestTokenizerInput(t *testing.T) {
+	input := writeInput(t, t.TempDir(), "in.txt", "Hello, World\n")
+
+	h, _ := runCount(t, "--tokenizer=whitespace", input)
+
+	for word, want := range map[string]int64{"Hello,": 1, "World": 1, "hello": 0} {
+		if got := countOf(h.words.universal, word); got != want {
+			t.Errorf("count of %q = %d, want %d", word, got, want)
+		}
+	}
+
+	tokenizers["reverse"] = func(normalizeOpts) tokenizer { return reverseTokenizer{} }
+	defer delete(tokenizers, "reverse")
+
+	h, _ = runCount(t, "--tokenizer=reverse", input)
+
+	if got := countOf(h.words.universal, "dlroW"); got != 1 {
+		t.Errorf("count of dlroW = %d, want 1", got)
+	}
+
+	err := execCount(newHandler(), "--tokenizer=icu", input)
+	if err == nil || !strings.Contains(err.Error(), "unsupported tokenizer") {
+		t.Errorf("expected an unsupported tokenizer error, got %v", err)
+	}
+}
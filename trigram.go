@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/alcionai/clues/cluerr"
+	"github.com/puzpuzpuz/xsync/v4"
+)
+
+const (
+	// trigramPad marks the start and end of each word, so that
+	// trigrams at word boundaries are distinct from those within.
+	trigramPad = "_"
+	// trigramProfileSize is the number of most frequent trigrams
+	// kept in a profile.  300 is the profile length used by the
+	// usual n-gram language identification methods.
+	trigramProfileSize = 300
+)
+
+// trigramProfile is the normalized character-trigram profile of
+// all counted words.
+type trigramProfile struct {
+	Total    int64   `json:"total"`
+	Trigrams []entry `json:"trigrams"`
+}
+
+// incTrigrams counts every character trigram of the word, once padded
+// at each end.
+func incTrigrams(
+	m *xsync.Map[string, *xsync.Counter],
+	total *xsync.Counter,
+	word string,
+) {
+	chars := graphemes(trigramPad + word + trigramPad)
+
+	for i := 0; i+3 <= len(chars); i++ {
+		incX(m, strings.Join(chars[i:i+3], ""))
+		total.Inc()
+	}
+}
+
+// writeTrigramProfile writes the most frequent trigrams, and their
+// share of all trigrams, to the file at path as json.
+func (h *handler) writeTrigramProfile(path string) error {
+	total := h.trigramCount.Value()
+
+	profile := trigramProfile{
+		Total:    total,
+		Trigrams: toEntries(h.trigrams, view{top: trigramProfileSize}, false, total),
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return cluerr.Wrap(err, "creating trigram profile file")
+	}
+
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(profile); err != nil {
+		return cluerr.Wrap(err, "encoding trigram profile")
+	}
+
+	return cluerr.Wrap(f.Close(), "closing trigram profile file").OrNil()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/puzpuzpuz/xsync/v4"
+)
+
+func TestIncTrigrams(t *testing.T) {
+	var (
+		m     = xsync.NewMap[string, *xsync.Counter]()
+		total = xsync.NewCounter()
+	)
+
+	incTrigrams(m, total, "the")
+	incTrigrams(m, total, "a")
+
+	var got []string
+
+	for _, u := range toUnitSlice(m) {
+		got = append(got, u.v)
+	}
+
+	if want := []string{"_a_", "_th", "he_", "the"}; !slices.Equal(got, want) {
+		t.Errorf("trigrams = %q, want %q", got, want)
+	}
+
+	if total.Value() != 4 {
+		t.Errorf("trigram total = %d, want 4", total.Value())
+	}
+}
+
+func TestTrigramProfileEnglish(t *testing.T) {
+	var (
+		dir     = t.TempDir()
+		profile = filepath.Join(dir, "profile.json")
+		input   = writeInput(t, dir, "in.txt", `The morning was bright, and the children were singing while
+walking to the meeting.  Nothing in the building was missing, and
+everything the others were bringing was waiting there, thinking
+of the evening and the long ending of the spring.
+`)
+	)
+
+	runCount(t, "--trigram-profile="+profile, input)
+
+	bs, err := os.ReadFile(profile)
+	if err != nil {
+		t.Fatalf("reading trigram profile: %v", err)
+	}
+
+	var p trigramProfile
+
+	if err := json.Unmarshal(bs, &p); err != nil {
+		t.Fatalf("unmarshalling trigram profile: %v", err)
+	}
+
+	var top []string
+
+	for _, e := range p.Trigrams[:10] {
+		top = append(top, e.Value)
+	}
+
+	for _, want := range []string{"the", "ing", "ng_"} {
+		if !slices.Contains(top, want) {
+			t.Errorf("top trigrams %q are missing %q", top, want)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestVersion(t *testing.T) {
+	out := runSubcommand(t, "version")
+
+	for _, want := range []string{
+		"count " + version + "\n",
+		"commit: " + commit + "\n",
+		"built: " + date + "\n",
+		"go: " + runtime.Version(),
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("version output is missing %q:\n%s", want, out)
+		}
+	}
+}
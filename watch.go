@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alcionai/clues/clog"
+	"github.com/alcionai/clues/cluerr"
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long watching waits, after a change, for any
+// further changes before recounting.  Saving a file often produces
+// several events in a row.
+const watchDebounce = 250 * time.Millisecond
+
+// watchInputs counts the inputs, then recounts them and reprints the
+// results every time any of them change, until the context is done.
+// Each recount starts over from scratch, and re-resolves the inputs,
+// so that files added to a watched directory get counted.  Failed
+// counts are logged, rather than stopping the watch.
+func (h *handler) watchInputs(ctx context.Context, args []string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return cluerr.WrapWC(ctx, err, "starting watcher")
+	}
+
+	defer watcher.Close()
+
+	watched, err := h.watchPaths(ctx, watcher, args)
+	if err != nil {
+		return err
+	}
+
+	if err := h.count(ctx, args); err != nil {
+		clog.CtxErr(ctx, err).Error("counting inputs")
+	}
+
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			if watched.relevant(ev.Name) {
+				debounce.Reset(watchDebounce)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+
+			clog.CtxErr(ctx, err).Error("watching inputs")
+
+		case <-debounce.C:
+			clog.Ctx(ctx).Info("inputs changed; recounting")
+
+			next := newHandler()
+
+			if err := next.parseFlags(); err != nil {
+				return cluerr.WrapWC(ctx, err, "parsing flags")
+			}
+
+			if err := next.count(ctx, args); err != nil {
+				clog.CtxErr(ctx, err).Error("counting inputs")
+			}
+
+			if watched, err = next.watchPaths(ctx, watcher, args); err != nil {
+				clog.CtxErr(ctx, err).Error("watching inputs")
+			}
+		}
+	}
+}
+
+// watchedInputs holds the inputs whose changes cause a recount.
+type watchedInputs struct {
+	inputs inputMatcher
+	// the absolute paths of every resolved input.
+	files map[string]struct{}
+	// the absolute paths of directories given as arguments, any new
+	// counted file within which also causes a recount.
+	dirs []string
+}
+
+// relevant is true if a change to the path should cause a recount.
+func (wi watchedInputs) relevant(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+
+	if _, ok := wi.files[abs]; ok {
+		return true
+	}
+
+	if !wi.inputs.counts(abs) {
+		return false
+	}
+
+	for _, dir := range wi.dirs {
+		if rel, err := filepath.Rel(dir, abs); err == nil && filepath.IsLocal(rel) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// watchPaths adds every input, and every directory given as an
+// argument, to the watcher.  Files are watched through their parent
+// directory, since many editors save a file by replacing it.
+func (h *handler) watchPaths(
+	ctx context.Context,
+	watcher *fsnotify.Watcher,
+	args []string,
+) (watchedInputs, error) {
+	wi := watchedInputs{
+		inputs: h.inputs,
+		files:  map[string]struct{}{},
+	}
+
+	files, err := h.resolveArgs(ctx, args)
+	if err != nil {
+		return wi, err
+	}
+
+	for _, file := range files {
+		if !isLocal(file) {
+			return wi, cluerr.NewWC(ctx, "only local files can be watched: "+file)
+		}
+
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return wi, cluerr.WrapWC(ctx, err, "watching file: "+file)
+		}
+
+		wi.files[abs] = struct{}{}
+
+		if err := watcher.Add(filepath.Dir(abs)); err != nil {
+			return wi, cluerr.WrapWC(ctx, err, "watching file: "+file)
+		}
+	}
+
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+
+		abs, err := filepath.Abs(arg)
+		if err != nil {
+			return wi, cluerr.WrapWC(ctx, err, "watching directory: "+arg)
+		}
+
+		wi.dirs = append(wi.dirs, abs)
+
+		// fsnotify doesn't watch recursively, so every subdirectory
+		// gets its own watch.
+		err = filepath.WalkDir(abs, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return err
+			}
+
+			if h.inputs.excluded(path) {
+				return filepath.SkipDir
+			}
+
+			return watcher.Add(path)
+		})
+		if err != nil {
+			return wi, cluerr.WrapWC(ctx, err, "watching directory: "+arg)
+		}
+	}
+
+	return wi, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestWatchedInputsRelevant(t *testing.T) {
+	dir := t.TempDir()
+
+	wi := watchedInputs{
+		inputs: inputMatcher{exts: []string{".txt"}},
+		files:  map[string]struct{}{filepath.Join(dir, "listed.md"): {}},
+		dirs:   []string{filepath.Join(dir, "corpus")},
+	}
+
+	table := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"listed file", filepath.Join(dir, "listed.md"), true},
+		{"new file in watched dir", filepath.Join(dir, "corpus", "new.txt"), true},
+		{"nested file in watched dir", filepath.Join(dir, "corpus", "sub", "new.txt"), true},
+		{"uncounted file in watched dir", filepath.Join(dir, "corpus", "new.md"), false},
+		{"counted file outside watched dirs", filepath.Join(dir, "other.txt"), false},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			if got := wi.relevant(test.path); got != test.want {
+				t.Errorf("relevant(%q) = %v, want %v", test.path, got, test.want)
+			}
+		})
+	}
+}
+
+func TestWatchPaths(t *testing.T) {
+	dir := writeTree(t, t.TempDir(), "corpus/a.txt", "corpus/sub/b.txt", "single.txt")
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer watcher.Close()
+
+	h := newHandler()
+	h.inputs = inputMatcher{exts: []string{".txt"}}
+
+	wi, err := h.watchPaths(
+		context.Background(),
+		watcher,
+		[]string{filepath.Join(dir, "corpus"), filepath.Join(dir, "single.txt")})
+	if err != nil {
+		t.Fatalf("watching paths: %v", err)
+	}
+
+	for _, file := range []string{"corpus/a.txt", "corpus/sub/b.txt", "single.txt"} {
+		if _, ok := wi.files[filepath.Join(dir, file)]; !ok {
+			t.Errorf("%s isn't watched", file)
+		}
+	}
+
+	if len(wi.dirs) != 1 || wi.dirs[0] != filepath.Join(dir, "corpus") {
+		t.Errorf("watched dirs = %v, want [%s]", wi.dirs, filepath.Join(dir, "corpus"))
+	}
+
+	watching := watcher.WatchList()
+
+	for _, want := range []string{dir, filepath.Join(dir, "corpus"), filepath.Join(dir, "corpus", "sub")} {
+		if !slices.Contains(watching, want) {
+			t.Errorf("%s isn't in the watch list %v", want, watching)
+		}
+	}
+}
+
+func TestWatchRejectsURLs(t *testing.T) {
+	srv := serveCorpus(t, "words\n")
+
+	err := execCount(newHandler(), "--watch", srv.URL+"/corpus.txt")
+	if err == nil || !strings.Contains(err.Error(), "only local files can be watched") {
+		t.Errorf("expected a local files error, got %v", err)
+	}
+}
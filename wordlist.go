@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alcionai/clues/cluerr"
+)
+
+// readWordList reads one word per line from the file at path.  Words
+// are lowercased to match normalized words.  Anything after a # is a
+// comment, and blank lines are skipped.  Duplicate words are only
+// kept once.
+func readWordList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, cluerr.Wrap(err, "opening word list")
+	}
+
+	defer f.Close()
+
+	var (
+		words   = []string{}
+		seen    = map[string]struct{}{}
+		scanner = bufio.NewScanner(f)
+	)
+
+	for scanner.Scan() {
+		word, _, _ := strings.Cut(scanner.Text(), "#")
+		word = strings.ToLower(strings.TrimSpace(word))
+
+		if len(word) == 0 {
+			continue
+		}
+
+		if _, ok := seen[word]; ok {
+			continue
+		}
+
+		seen[word] = struct{}{}
+		words = append(words, word)
+	}
+
+	return words, cluerr.Wrap(scanner.Err(), "reading word list").OrNil()
+}
+
+// printReportWords writes the raw counts of each listed word, in the
+// order they were listed.  Words that were never counted get a row
+// of zeros.
+func printReportWords(
+	stats stats,
+	words []string,
+	count func(string) int64,
+	w io.Writer,
+) {
+	total := stats.count.Value()
+
+	writeLn(w, "reported words")
+	writeLn(w, "| word "+addCellHeader("raw", total)+"|")
+	writeLn(w, "|---|---|")
+
+	for _, word := range words {
+		n := count(word)
+
+		writeLn(w, fmt.Sprintf(
+			"| %5s | %6s, %2.2f%% |",
+			word,
+			human(n),
+			percent(int(n), total),
+		))
+	}
+}
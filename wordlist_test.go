@@ -0,0 +1,70 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadWordList(t *testing.T) {
+	path := writeInput(t, t.TempDir(), "words.txt", "The\n# a comment\n\ncat # inline comment\nTHE\ndog\n")
+
+	words, err := readWordList(path)
+	if err != nil {
+		t.Fatalf("reading word list: %v", err)
+	}
+
+	if want := []string{"the", "cat", "dog"}; !slices.Equal(words, want) {
+		t.Errorf("words = %q, want %q", words, want)
+	}
+}
+
+func TestReportWordsFile(t *testing.T) {
+	var (
+		dir   = t.TempDir()
+		list  = writeInput(t, dir, "words.txt", "cat\nzebra\nthe\n")
+		input = writeInput(t, dir, "in.txt", "the cat the dog\n")
+	)
+
+	_, report := runCount(t, "--report-words-file="+list, input)
+
+	want := strings.Join([]string{
+		"reported words",
+		"| word | raw (4) |",
+		"|---|---|",
+		"|   cat |      1, 25.00% |",
+		"| zebra |      0, 0.00% |",
+		"|   the |      2, 50.00% |",
+	}, "\n")
+
+	if !strings.Contains(report, want) {
+		t.Errorf("report is missing the reported words, with a zero row for zebra:\n%s", report)
+	}
+}
+
+func TestRemoveWordsFile(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		list   = writeInput(t, dir, "remove.txt", "# chapter markers\nChapter\nDon't # contractions match too\n")
+		input  = writeInput(t, dir, "in.txt", "chapter one dont stop\n")
+		counts = map[string]int64{"chapter": 0, "dont": 0, "one": 0, "stop": 1}
+	)
+
+	h, _ := runCount(t, "--removeWordsFile="+list, "-r=one", input)
+
+	for word, want := range counts {
+		if got := countOf(h.words.removed, word); got != want {
+			t.Errorf("count of %q after removal = %d, want %d", word, got, want)
+		}
+	}
+
+	if got := h.words.countRemoved.Value(); got != 3 {
+		t.Errorf("removed word count = %d, want 3", got)
+	}
+
+	err := execCount(newHandler(), "--removeWordsFile="+filepath.Join(dir, "missing.txt"), input)
+	if err == nil || !strings.Contains(err.Error(), "loading remove words") {
+		t.Errorf("expected a loading error, got %v", err)
+	}
+}